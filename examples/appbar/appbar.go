@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the top app bars in gio-v.
+// Scroll the list to see the bar collapse and get a shadow.
+
+import (
+	"fmt"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+var (
+	theme   *wid.Theme
+	form    layout.Widget
+	win     app.Window
+	style   = int(wid.LargeAppBar)
+	status  = "Scroll the list"
+	starred bool
+	// pos is the scroll position of the list, used by the app bar
+	pos int
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v top app bar"), app.Size(unit.Dp(500), unit.Dp(700)))
+	form = appbar(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func icon(data []byte) *wid.Icon {
+	ic, _ := wid.NewIcon(data)
+	return ic
+}
+
+// do returns a function that shows the command in the first line of the list
+func do(s string) func() {
+	return func() {
+		status = s
+	}
+}
+
+func appbar(th *wid.Theme) layout.Widget {
+	rows := []layout.Widget{wid.Label(th, &status)}
+	for i := 1; i <= 50; i++ {
+		rows = append(rows, wid.Label(th, fmt.Sprintf("Message number %d", i)))
+	}
+	return wid.TopAppBar(th, "Inbox", wid.ScrollList(th, wid.Overlay, &pos, rows...),
+		wid.ScrollPos(&pos),
+		wid.BarStyle(wid.AppBarStyle(style)),
+		wid.NavIcon(icon(icons.NavigationMenu), do("Menu")),
+		wid.Actions(
+			wid.ToolDropDown(th, &style, []string{"Small", "Center", "Medium", "Large"}, "Style", wid.Do(func() {
+				// The form is made again with the new style
+				form = appbar(th)
+			})),
+			wid.ToolToggle(th, icon(icons.ToggleStar), "Starred", &starred),
+			wid.ToolButton(th, icon(icons.ActionSearch), "Search", wid.Do(do("Search"))),
+			wid.ToolButton(th, icon(icons.ContentArchive), "Archive", wid.Do(do("Archive"))),
+			wid.ToolButton(th, icon(icons.ActionDelete), "Delete", wid.Do(do("Delete"))),
+			wid.ToolButton(th, icon(icons.ActionSettings), "Settings", wid.Do(do("Settings"))),
+		),
+	)
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the code editor in gio-v.
+
+import (
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme  *wid.Theme
+	form   layout.Widget
+	win    app.Window
+	goCode = `package main
+
+import "fmt"
+
+// main prints a greeting
+func main() {
+	for i := 0; i < 3; i++ {
+		fmt.Println("Hello", i, true)
+	}
+}
+`
+	config = `# Server configuration
+server:
+  host: "localhost"
+  port: 8080
+  debug: false
+users:
+  - name: admin
+    roles: [read, write]
+`
+	settings = `{"theme": "dark", "size": 16, "recent": ["a.go", "b.go"], "proxy": null}`
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v code editor"), app.Size(unit.Dp(700), unit.Dp(900)))
+	form = code(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func code(th *wid.Theme) layout.Widget {
+	return wid.List(th, wid.Occupy,
+		wid.Label(th, "Code editor", wid.Heading(), wid.Middle()),
+		wid.Label(th, "Go source (Ctrl+F to find, Ctrl+H to replace)", wid.Large()),
+		wid.CodeEdit(th, &goCode, wid.Tokens(wid.GoTokens), wid.CodeLines(12)),
+		wid.Label(th, "YAML", wid.Large()),
+		wid.CodeEdit(th, &config, wid.Tokens(wid.YAMLTokens), wid.TabWidth(2), wid.CodeLines(9)),
+		wid.Label(th, "JSON", wid.Large()),
+		wid.CodeEdit(th, &settings, wid.Tokens(wid.JSONTokens), wid.CodeLines(3)),
+	)
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates modal dialogs in gio-v.
+// Dialogs can be shown on top of other dialogs, and a worker goroutine can wait for the result.
+// Enter clicks the default button, and Escape closes the dialog on top.
+// The form dialogs only change the variables when OK is clicked.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme  *wid.Theme
+	form   layout.Widget
+	win    app.Window
+	status = "No dialog shown yet"
+	name   = "Ola Nordmann"
+	age    = 42
+	color  = "Blue"
+	colors = []string{"Red", "Green", "Blue", "Yellow", "Black", "White"}
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v dialogs"), app.Size(unit.Dp(700), unit.Dp(500)))
+	form = dialogs(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func setStatus(s string) {
+	wid.GuiLock.Lock()
+	status = s
+	wid.GuiLock.Unlock()
+	wid.Invalidate()
+}
+
+// settings is a dialog that can open a second dialog on top of itself
+func settings(th *wid.Theme) layout.Widget {
+	return wid.Dialog(th, wid.PrimaryContainer,
+		wid.Label(th, "Settings", wid.Heading(), wid.Middle()),
+		wid.Label(th, "The reset button opens a confirmation on top of this dialog."),
+		wid.Row(th, nil, wid.SpaceRightAdjust,
+			wid.TextButton(th, "Reset", wid.Do(func() {
+				r := wid.ShowModal(confirm(th, "Reset all settings?"))
+				go func() {
+					if (<-r).Button == 1 {
+						setStatus("Settings reset")
+					}
+				}()
+			})),
+			wid.DialogButton(th, "Close", wid.Cancelled),
+		),
+	)
+}
+
+func confirm(th *wid.Theme, text string) layout.Widget {
+	return wid.Dialog(th, wid.PrimaryContainer,
+		wid.Label(th, "Confirm", wid.Heading(), wid.Middle()),
+		wid.Label(th, text, wid.Middle()),
+		wid.Row(th, nil, wid.SpaceRightAdjust,
+			wid.DialogButton(th, "Cancel", wid.Cancelled),
+			wid.DefaultButton(th, "OK", 1),
+		),
+	)
+}
+
+// job is a worker goroutine asking for confirmation before each step
+func job(th *wid.Theme) {
+	for i := 1; i <= 3; i++ {
+		r := <-wid.ShowModal(confirm(th, fmt.Sprintf("Do step %d of 3?", i)))
+		if r.Button == wid.Cancelled {
+			setStatus(fmt.Sprintf("Job cancelled at step %d", i))
+			return
+		}
+		setStatus(fmt.Sprintf("Working on step %d", i))
+		time.Sleep(time.Second)
+	}
+	setStatus("Job done")
+}
+
+func notEmpty(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "Must be given"
+	}
+	return ""
+}
+
+func validAge(s string) string {
+	if a, err := strconv.Atoi(s); err != nil || a < 0 || a > 150 {
+		return "Must be a number from 0 to 150"
+	}
+	return ""
+}
+
+// person is a form dialog editing copies of name and age
+func person(th *wid.Theme) layout.Widget {
+	d := &wid.Draft{}
+	return wid.FormDialog(th, "Person", d,
+		wid.Edit(th, wid.Bind(d, &name), wid.OutlinedField, wid.Lbl("Name"), wid.Validate(notEmpty)),
+		wid.Edit(th, wid.Bind(d, &age), wid.OutlinedField, wid.Lbl("Age"), wid.Validate(validAge)),
+	)
+}
+
+func dialogs(th *wid.Theme) layout.Widget {
+	return wid.Col(wid.SpaceClose,
+		wid.Label(th, "Modal dialogs", wid.Heading(), wid.Middle()),
+		wid.Label(th, &status, wid.Middle()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Button(th, "Settings", wid.Do(func() { wid.Show(settings(th)) })),
+			wid.Button(th, "Run job", wid.Do(func() { go job(th) })),
+		),
+		wid.Label(th, "Form dialogs", wid.Heading(), wid.Middle()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Label(th, &name),
+			wid.Label(th, &age),
+			wid.Label(th, &color),
+		),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Button(th, "Name", wid.Do(func() { wid.Show(wid.PromptDialog(th, "Name", "Name", &name, notEmpty)) })),
+			wid.Button(th, "Color", wid.Do(func() { wid.Show(wid.ChoiceDialog(th, "Color", &color, colors)) })),
+			wid.Button(th, "Person", wid.Do(func() { wid.Show(person(th)) })),
+		),
+	)
+}
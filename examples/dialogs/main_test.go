@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/jkvatne/gio-v/wid"
+	"image"
+	"testing"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+
+	"gioui.org/font/gofont"
+)
+
+func TestDialogs(t *testing.T) {
+	theme = wid.NewTheme(gofont.Collection(), 14)
+	gtx := layout.Context{
+		Ops: new(op.Ops),
+		// Rigid constraints with both minimum and maximum set.
+		Constraints: layout.Exact(image.Point{X: 500, Y: 400}),
+	}
+	form = dialogs(theme)
+	form(gtx)
+}
+
+func BenchmarkDialogs(b *testing.B) {
+	theme = wid.NewTheme(gofont.Collection(), 14)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	form = dialogs(theme)
+	for i := 0; i < b.N; i++ {
+		gtx := layout.Context{
+			Ops: new(op.Ops),
+			// Rigid constraints with both minimum and maximum set.
+			Constraints: layout.Exact(image.Point{X: 500, Y: 400}),
+		}
+		form(gtx)
+	}
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the Material 3 text fields in gio-v.
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+var (
+	theme     *wid.Theme
+	form      layout.Widget
+	win       app.Window
+	searchIcn *wid.Icon
+	name      = "Jan Kåre"
+	email     string
+	password  string
+	price     = 12.5
+	weight    = 80
+	comment   string
+	date      string
+	ipAddr    = "192.168.0.1"
+	macAddr   string
+	plate     string
+	search    string
+	status    = "Type in the search field"
+	tag       string
+	path      string
+	tags      = []string{"bug", "build", "design", "docs", "duplicate", "feature", "help wanted", "question"}
+	history   = wid.NewHistory(100)
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v text fields"), app.Size(unit.Dp(600), unit.Dp(700)))
+	form = fields(theme)
+	// Record all changes, so they can be undone with Ctrl+Z or the Undo button
+	wid.TrackChanges(history)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func onSearch(text string) {
+	status = "Searching for " + text
+}
+
+func onSearchSubmit(text string) {
+	status = "Search submitted: " + text
+}
+
+// suggestPath returns the files and directories starting with the given path.
+// Reading the directory can be slow, but the edit calls it in a separate goroutine.
+func suggestPath(prefix string) []string {
+	dir, file := filepath.Split(prefix)
+	entries, err := os.ReadDir(filepath.Clean(dir + "."))
+	if err != nil {
+		return nil
+	}
+	var found []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), file) {
+			s := dir + e.Name()
+			if e.IsDir() {
+				s += string(filepath.Separator)
+			}
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+func fields(th *wid.Theme) layout.Widget {
+	searchIcn, _ = wid.NewIcon(icons.ActionSearch)
+	return wid.List(th, wid.Occupy,
+		wid.Label(th, "Text fields", wid.Heading(), wid.Middle()),
+		wid.Edit(th, &name, wid.OutlinedField, wid.Lbl("Name"), wid.ClearBtn()),
+		wid.Edit(th, &email, wid.FilledField, wid.Lbl("E-mail"), wid.Hint("name@example.com"),
+			wid.Helper("Used for notifications only")),
+		wid.Edit(th, &password, wid.OutlinedField, wid.Lbl("Password"), wid.PwdToggle()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Edit(th, &price, 2, wid.OutlinedField, wid.Lbl("Price"), wid.Prefix("$ ")),
+			wid.Edit(th, &weight, wid.FilledField, wid.Lbl("Weight"), wid.Suffix(" kg")),
+		),
+		wid.Edit(th, &comment, wid.OutlinedField, wid.Lbl("Comment"), wid.LeadIcon(searchIcn), wid.Counter(40)),
+		wid.Label(th, "Input masks", wid.Large()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Edit(th, &date, wid.OutlinedField, wid.Lbl("Date"), wid.Masked(wid.MaskDate), wid.RawValue()),
+			wid.Edit(th, &plate, wid.OutlinedField, wid.Lbl("Licence plate"), wid.Masked("AA 99999")),
+		),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Edit(th, &ipAddr, wid.OutlinedField, wid.Lbl("IP address"), wid.Masked(wid.MaskIPv4)),
+			wid.Edit(th, &macAddr, wid.OutlinedField, wid.Lbl("MAC address"), wid.Masked(wid.MaskMAC)),
+		),
+		wid.Label(th, "Live search", wid.Large()),
+		wid.Edit(th, &search, wid.OutlinedField, wid.Lbl("Search"), wid.LeadIcon(searchIcn), wid.Live(),
+			wid.Debounce(300*time.Millisecond), wid.OnChange(onSearch), wid.OnSubmit(onSearchSubmit)),
+		wid.Label(th, &status),
+		wid.Label(th, "Autocomplete", wid.Large()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Edit(th, &tag, wid.OutlinedField, wid.Lbl("Tag"), wid.Suggest(wid.SuggestFrom(tags))),
+			wid.Edit(th, &path, wid.OutlinedField, wid.Lbl("File"), wid.Suggest(suggestPath)),
+		),
+		wid.Edit(th, &name, wid.Lbl("Plain edit"), wid.Ls(0.3)),
+		wid.Label(th, "Read-only", wid.Large()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Edit(th, &ipAddr, wid.OutlinedField, wid.Lbl("Server address"), wid.ReadOnly()),
+			wid.Edit(th, &ipAddr, wid.FilledField, wid.Lbl("Server address"), wid.ReadOnly()),
+		),
+		wid.Label(th, "This label text can be selected and copied with Ctrl+C", wid.Selectable()),
+		wid.Label(th, "Rich text", wid.Large()),
+		wid.RichText(th, []wid.Span{
+			wid.Txt("Rich text can mix "), wid.Txt("bold").Bold(), wid.Txt(", "), wid.Txt("italic").Italic(),
+			wid.Txt(", "), wid.Txt("colored").Color(wid.Error), wid.Txt(" and "), wid.Txt("larger").Scale(1.4),
+			wid.Txt(" text with "), wid.Txt("code()").Mono().Highlight(wid.SurfaceContainerHighest),
+			wid.Txt(" and "), wid.Link("links", func() { status = "The link was clicked" }), wid.Txt("."),
+		}),
+		wid.Row(th, nil, wid.SpaceClose,
+			wid.OutlineButton(th, "Undo", wid.Do(func() { history.Undo() })),
+			wid.OutlineButton(th, "Redo", wid.Do(func() { history.Redo() })),
+		),
+	)
+}
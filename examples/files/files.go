@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the file dialog in gio-v.
+// The dialog is drawn with gio-v widgets, and browses any io/fs file system. Here it is the disk,
+// starting in the current directory. The table is sorted by clicking the column headings.
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme  *wid.Theme
+	form   layout.Widget
+	win    app.Window
+	status = "No file selected"
+	// root is the root of the file system, like / or C:/
+	root = "/"
+	// start is the current directory, relative to root
+	start = "."
+	// openDialog and saveDialog are the file dialogs
+	openDialog, saveDialog layout.Widget
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v file dialog"), app.Size(unit.Dp(900), unit.Dp(700)))
+	if wd, err := os.Getwd(); err == nil {
+		root = filepath.VolumeName(wd) + "/"
+		start = strings.TrimPrefix(filepath.ToSlash(wd), root)
+	}
+	form = files(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func setStatus(s string) {
+	wid.GuiLock.Lock()
+	status = s
+	wid.GuiLock.Unlock()
+	wid.Invalidate()
+}
+
+// choose shows the dialog, and waits for the file chosen
+func choose(d layout.Widget, verb string) {
+	r := wid.ShowModal(d)
+	go func() {
+		res := <-r
+		if res.Button == wid.Cancelled {
+			setStatus("Cancelled")
+			return
+		}
+		setStatus(verb + " " + filepath.FromSlash(root+res.Value.(string)))
+	}()
+}
+
+func files(th *wid.Theme) layout.Widget {
+	fsys := os.DirFS(root)
+	filters := wid.Filters(
+		wid.FileFilter{Name: "Go files", Patterns: []string{"*.go"}},
+		wid.FileFilter{Name: "Text files", Patterns: []string{"*.txt", "*.md"}},
+		wid.FileFilter{Name: "All files", Patterns: []string{"*"}},
+	)
+	openDialog = wid.FileDialog(th, fsys, "Open file", filters, wid.StartDir(start))
+	saveDialog = wid.FileDialog(th, fsys, "Save file", filters, wid.StartDir(start), wid.SaveMode(), wid.FileName("main.go"))
+	return wid.Col(wid.SpaceClose,
+		wid.Label(th, "File dialog", wid.Heading(), wid.Middle()),
+		wid.Label(th, &status, wid.Middle()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Button(th, "Open...", wid.Do(func() { choose(openDialog, "Open") })),
+			wid.Button(th, "Save as...", wid.Do(func() { choose(saveDialog, "Save") })),
+		),
+	)
+}
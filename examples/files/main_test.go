@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/jkvatne/gio-v/wid"
+	"image"
+	"testing"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+
+	"gioui.org/font/gofont"
+)
+
+func TestFiles(t *testing.T) {
+	theme = wid.NewTheme(gofont.Collection(), 14)
+	gtx := layout.Context{
+		Ops: new(op.Ops),
+		// Rigid constraints with both minimum and maximum set.
+		Constraints: layout.Exact(image.Point{X: 500, Y: 400}),
+	}
+	form = files(theme)
+	form(gtx)
+	// Draw the dialogs, listing the directory
+	for _, d := range []layout.Widget{openDialog, saveDialog} {
+		wid.ShowModal(d)
+		d(gtx)
+		wid.Hide()
+	}
+}
+
+func BenchmarkFiles(b *testing.B) {
+	theme = wid.NewTheme(gofont.Collection(), 14)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	form = files(theme)
+	for i := 0; i < b.N; i++ {
+		gtx := layout.Context{
+			Ops: new(op.Ops),
+			// Rigid constraints with both minimum and maximum set.
+			Constraints: layout.Exact(image.Point{X: 500, Y: 400}),
+		}
+		form(gtx)
+	}
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the markdown widget, used for in-app help.
+
+import (
+	"fmt"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme *wid.Theme
+	form  layout.Widget
+	win   app.Window
+)
+
+const helpText = `# Gio-v help
+
+This help text is written in **markdown**, and shown by the *Markdown* widget.
+Use it for help pages and release notes.
+
+## Text styles
+
+Text can be *italic*, **bold** or ***both***. Inline code like ` + "`wid.Markdown(th, source)`" + ` uses a
+monospace font. Links like [the Gio home page](https://gioui.org) call the OnLink handler.
+
+## Lists
+
+- Bullet lists use -, * or +
+- Long items are wrapped with a hanging indent, so that the text lines up nicely
+  even when the item continues on the next line
+  - Nested items are indented
+1. Numbered lists
+2. keep the numbers given
+
+---
+
+## Code
+
+` + "```" + `
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	form = wid.Markdown(theme, helpText)
+}
+` + "```" + `
+`
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v help"), app.Size(unit.Dp(600), unit.Dp(700)))
+	form = help(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func help(th *wid.Theme) layout.Widget {
+	return wid.Markdown(th, helpText, wid.OnLink(func(url string) {
+		fmt.Println("Link clicked:", url)
+	}))
+}
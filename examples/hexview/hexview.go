@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the hex viewer in gio-v.
+
+import (
+	"bytes"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme *wid.Theme
+	form  layout.Widget
+	win   app.Window
+	// packet is an UDP datagram with an IPv4 header
+	packet = []byte{
+		0x45, 0x00, 0x00, 0x2c, 0x1c, 0x46, 0x40, 0x00, 0x40, 0x11, 0xb1, 0xe6, 0xc0, 0xa8, 0x00, 0x68,
+		0xc0, 0xa8, 0x00, 0x01, 0xd4, 0x31, 0x00, 0x35, 0x00, 0x18, 0x8f, 0x2b, 'H', 'e', 'l', 'l',
+		'o', ' ', 'f', 'r', 'o', 'm', ' ', 'g', 'i', 'o', '-', 'v',
+	}
+	fields = []wid.ByteRange{
+		{Start: 12, End: 16, Role: wid.Primary},
+		{Start: 16, End: 20, Role: wid.Secondary},
+		{Start: 20, End: 28, Role: wid.Tertiary},
+	}
+	large []byte
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v hex viewer"), app.Size(unit.Dp(900), unit.Dp(800)))
+	form = hexview(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func hexview(th *wid.Theme) layout.Widget {
+	if large == nil {
+		large = make([]byte, 1<<20)
+		for i := range large {
+			large[i] = byte(i * 7)
+		}
+	}
+	return wid.List(th, wid.Occupy,
+		wid.Label(th, "Hex viewer", wid.Heading(), wid.Middle()),
+		wid.Label(th, "UDP packet, with source address, destination address and UDP header highlighted. Type to edit.", wid.Large()),
+		wid.HexView(th, &packet, wid.HexEditable(), wid.Highlights(&fields), wid.HexLines(4)),
+		wid.Label(th, "One megabyte read through an io.ReaderAt. Use Ctrl+G to go to an offset.", wid.Large()),
+		wid.HexReader(th, bytes.NewReader(large), int64(len(large)), wid.HexLines(16)),
+	)
+}
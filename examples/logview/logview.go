@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the log viewer in gio-v.
+// A background goroutine adds lines, and the standard logger writes to the same buffer.
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme *wid.Theme
+	form  layout.Widget
+	win   app.Window
+	buf   = wid.NewLogBuffer(200000)
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v log viewer"), app.Size(unit.Dp(900), unit.Dp(700)))
+	log.SetOutput(buf)
+	form = logview(theme)
+	go producer()
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+// producer simulates a program writing a steady stream of log lines
+func producer() {
+	for n := 0; ; n++ {
+		time.Sleep(20 * time.Millisecond)
+		switch r := rand.Intn(20); {
+		case r == 0:
+			buf.Printf(wid.LogError, "Request %d failed: connection reset", n)
+		case r < 3:
+			buf.Printf(wid.LogWarning, "Request %d took %d ms", n, 500+rand.Intn(1000))
+		case r < 10:
+			buf.Printf(wid.LogDebug, "Request %d headers parsed", n)
+		default:
+			buf.Printf(wid.LogInfo, "Request %d handled in %d ms", n, rand.Intn(100))
+		}
+	}
+}
+
+// burst adds many lines at once, from several goroutines
+func burst() {
+	for g := 0; g < 4; g++ {
+		go func(g int) {
+			for i := 0; i < 25000; i++ {
+				buf.Printf(wid.LogInfo, "Burst %d line %d", g, i)
+			}
+		}(g)
+	}
+}
+
+func logview(th *wid.Theme) layout.Widget {
+	return wid.List(th, wid.Occupy,
+		wid.Label(th, "Log viewer", wid.Heading(), wid.Middle()),
+		wid.Label(th, "Scroll up to stop following the log. Drag to select lines, and Ctrl+C to copy.", wid.Middle()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.OutlineButton(th, "Add 100 000 lines", wid.Do(burst)),
+			wid.OutlineButton(th, "Log an error", wid.Do(func() { log.Printf("ERROR: button pressed") })),
+			wid.OutlineButton(th, "Log a warning", wid.Do(func() { log.Printf("WARN: button pressed") })),
+		),
+		wid.LogView(th, buf, wid.LogLines(25)),
+	)
+}
@@ -40,7 +40,7 @@ func main() {
 		"Save data?",
 		"Click yes if you want to save the data to persitant memory or disk",
 		"No", "Yes",
-		onNo, onYes)
+		nil, nil)
 	theme.Scale = 1
 	go ticker()
 	win.Option(app.Title("Gio-v demo"), app.Size(unit.Dp(500), unit.Dp(600)))
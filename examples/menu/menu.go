@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates a menu bar with accelerators in gio-v.
+
+import (
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme  *wid.Theme
+	form   layout.Widget
+	win    app.Window
+	status = "Select a menu item, or press an accelerator"
+	text   = "Accelerators work also when this field has focus"
+	saved  = true
+	help   layout.Widget
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v menu"), app.Size(unit.Dp(800), unit.Dp(600)))
+	form = menu(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+// do returns a function that shows the command in the status line
+func do(s string) func() {
+	return func() {
+		status = s
+	}
+}
+
+func save() {
+	status = "Saved"
+	saved = true
+}
+
+func edited() {
+	saved = false
+}
+
+func darkMode() {
+	theme.DarkMode = !theme.DarkMode
+	theme.UpdateColors()
+}
+
+// showShortcuts shows a dialog with the shortcuts that are active in the form
+func showShortcuts() {
+	rows := []layout.Widget{wid.Label(theme, "Keyboard shortcuts", wid.Heading(), wid.Middle())}
+	for _, s := range wid.Shortcuts() {
+		if s.Active {
+			rows = append(rows, wid.Row(theme, []float32{1, 2}, wid.Label(theme, s.Keys), wid.Label(theme, s.Desc)))
+		}
+	}
+	rows = append(rows, wid.Row(theme, wid.SpaceRightAdjust, wid.TextButton(theme, "Close", wid.Do(wid.Hide))))
+	help = wid.Dialog(theme, wid.PrimaryContainer, rows...)
+	wid.Show(helpScope.Layout)
+}
+
+// helpScope has the shortcuts used while the help dialog is shown
+var helpScope = wid.Scope(func(gtx layout.Context) layout.Dimensions {
+	return help(gtx)
+})
+
+func menu(th *wid.Theme) layout.Widget {
+	// The shortcuts of the form are only active when no dialog is shown
+	scope := wid.Scope(page(th))
+	_, _ = wid.Shortcut("Ctrl+L", func() { text = "" }, wid.In(scope), wid.Desc("Clear the text"))
+	// The form may be rebuilt, so the old help shortcuts are removed first
+	helpScope.RemoveShortcuts()
+	_, _ = wid.Shortcut("Esc", wid.Hide, wid.In(helpScope), wid.Desc("Close the dialog"))
+	return scope.Layout
+}
+
+func page(th *wid.Theme) layout.Widget {
+	return wid.Col(wid.SpaceClose,
+		wid.MenuBar(th,
+			wid.Menu(th, "&File",
+				wid.MenuItem(th, "&New", wid.Do(do("New")), wid.Accel("Ctrl+N")),
+				wid.MenuItem(th, "&Open...", wid.Do(do("Open")), wid.Accel("Ctrl+O")),
+				wid.Menu(th, "Open &recent",
+					wid.MenuItem(th, "notes.txt", wid.Do(do("Open notes.txt"))),
+					wid.MenuItem(th, "todo.md", wid.Do(do("Open todo.md"))),
+				),
+				wid.MenuSeparator(th),
+				wid.MenuItem(th, "&Save", wid.Do(save), wid.Accel("Ctrl+S"), wid.En(&saved)),
+				wid.MenuItem(th, "Save &as...", wid.Do(do("Save as")), wid.Accel("Ctrl+Shift+S")),
+				wid.MenuSeparator(th),
+				wid.MenuItem(th, "E&xit", wid.Do(func() { os.Exit(0) }), wid.Accel("Alt+F4")),
+			),
+			wid.Menu(th, "&Edit",
+				wid.MenuItem(th, "&Find...", wid.Do(do("Find")), wid.Accel("Ctrl+F")),
+				wid.MenuItem(th, "&Replace...", wid.Do(do("Replace")), wid.Accel("Ctrl+H")),
+				wid.MenuSeparator(th),
+				wid.MenuItem(th, "&Preferences", wid.Do(do("Preferences"))),
+			),
+			wid.Menu(th, "&View",
+				wid.MenuItem(th, "Zoom &in", wid.Do(func() { theme.Scale *= 1.1 }), wid.Accel("Ctrl++")),
+				wid.MenuItem(th, "Zoom &out", wid.Do(func() { theme.Scale /= 1.1 }), wid.Accel("Ctrl+-")),
+				wid.Menu(th, "&Appearance",
+					wid.MenuItem(th, "&Dark mode", wid.Do(darkMode), wid.Accel("Ctrl+D")),
+					wid.MenuItem(th, "&Word wrap", wid.Do(do("Word wrap"))),
+				),
+			),
+			wid.Menu(th, "&Help",
+				wid.MenuItem(th, "&Keyboard shortcuts", wid.Do(showShortcuts), wid.Accel("F1")),
+				wid.MenuItem(th, "&About", wid.Do(do("Gio-v menu demo"))),
+			),
+		),
+		wid.Label(th, "Menu bar", wid.Heading(), wid.Middle()),
+		wid.Label(th, "Alt and the underlined letter opens a menu. The arrow keys move between items and menus."),
+		wid.Label(th, "F1 shows the keyboard shortcuts, and Ctrl+L clears the text."),
+		wid.Edit(th, &text, wid.Lbl("Text"), wid.Do(edited)),
+		wid.Label(th, &status),
+	)
+}
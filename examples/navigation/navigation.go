@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates a navigation drawer and a navigation rail in gio-v.
+// The drawer selects the page, and the first page has a rail selecting between folders.
+
+import (
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+var (
+	theme      *wid.Theme
+	form       layout.Widget
+	win        app.Window
+	page       int
+	folder     int
+	drawerOpen bool
+	unread     = 12
+	drafts     = 2
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v navigation"), app.Size(unit.Dp(900), unit.Dp(600)))
+	form = navigation(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func icon(data []byte) *wid.Icon {
+	ic, _ := wid.NewIcon(data)
+	return ic
+}
+
+func text(th *wid.Theme, s string) layout.Widget {
+	return wid.Col(wid.SpaceClose,
+		wid.Label(th, s, wid.Heading()),
+		wid.Label(th, "Select another page in the menu at the top left, or with the arrow keys."),
+	)
+}
+
+func mail(th *wid.Theme) layout.Widget {
+	return wid.NavigationRail(th, &folder, []wid.NavItem{
+		{Text: "Inbox", Icon: icon(icons.ContentInbox), Badge: &unread, Page: wid.Col(wid.SpaceClose,
+			wid.Label(th, "Inbox", wid.Heading()),
+			wid.Label(th, &unread),
+			wid.TextButton(th, "Read all", wid.Do(func() { unread = 0 })),
+		)},
+		{Text: "Drafts", Icon: icon(icons.ContentDrafts), Badge: &drafts, Page: text(th, "Drafts")},
+		{Text: "Sent", Icon: icon(icons.ContentSend), Page: text(th, "Sent")},
+		{Text: "Trash", Icon: icon(icons.ActionDelete), Page: text(th, "Trash")},
+	})
+}
+
+func navigation(th *wid.Theme) layout.Widget {
+	return wid.Col(wid.SpaceClose,
+		wid.Row(th, nil, wid.SpaceClose,
+			wid.RoundButton(th, icon(icons.NavigationMenu), wid.Do(func() { drawerOpen = true }), wid.Hint("Open the navigation drawer")),
+			wid.Label(th, "Navigation", wid.Heading()),
+		),
+		wid.NavigationDrawer(th, &page, []wid.NavItem{
+			{Text: "Mail", Icon: icon(icons.CommunicationEmail), Badge: &unread, Page: mail(th)},
+			{Text: "Calendar", Icon: icon(icons.ActionEvent), Page: text(th, "Calendar")},
+			{Text: "Contacts", Icon: icon(icons.SocialPeople), Page: text(th, "Contacts")},
+			{Text: "Settings", Icon: icon(icons.ActionSettings), Page: text(th, "Settings")},
+		}, wid.ModalDrawer(&drawerOpen), wid.NavTitle("Gio-v")),
+	)
+}
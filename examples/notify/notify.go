@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates notifications (snackbars) in gio-v.
+// Notifications are queued, and shown one at a time at the bottom of the window.
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme   *wid.Theme
+	form    layout.Widget
+	win     app.Window
+	deleted int
+	status  = "Click the buttons to queue notifications"
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v notifications"), app.Size(unit.Dp(700), unit.Dp(500)))
+	form = notify(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func onDelete() {
+	deleted++
+	wid.Notify(fmt.Sprintf("Message %d deleted", deleted), wid.NotifyAction("Undo", func() {
+		status = "Undo clicked"
+	}))
+}
+
+func onError() {
+	wid.Notify("Could not connect to the server", wid.Role(wid.Error), wid.NotifyTime(0))
+}
+
+// onBackground queues notifications from a goroutine, as a long running job would
+func onBackground() {
+	go func() {
+		for i := 1; i <= 3; i++ {
+			time.Sleep(500 * time.Millisecond)
+			wid.Notify(fmt.Sprintf("Background job step %d of 3 done", i), wid.NotifyTime(2*time.Second))
+		}
+	}()
+}
+
+func notify(th *wid.Theme) layout.Widget {
+	return wid.Col(wid.SpaceClose,
+		wid.Label(th, "Notifications", wid.Heading(), wid.Middle()),
+		wid.Label(th, &status, wid.Middle()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Button(th, "Delete", wid.Do(onDelete)),
+			wid.Button(th, "Error", wid.Do(onError), wid.Role(wid.Error)),
+			wid.Button(th, "Background job", wid.Do(onBackground)),
+		),
+	)
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates the date, time and color pickers in gio-v.
+
+import (
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme    *wid.Theme
+	form     layout.Widget
+	win      app.Window
+	birthday = time.Date(1990, 5, 17, 0, 0, 0, 0, time.Local)
+	meeting  = time.Now()
+	vacation time.Time
+	vacEnd   time.Time
+	status   = "Select a date"
+	alarm    = time.Date(2024, 1, 1, 6, 30, 0, 0, time.Local)
+	lapTime  = 95 * time.Second
+	picked   = wid.RGB(0x6750A4)
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v pickers"), app.Size(unit.Dp(600), unit.Dp(700)))
+	form = pickers(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func onDate() {
+	status = "Meeting at " + meeting.Format("Monday 2. January 2006 15:04")
+}
+
+func pickers(th *wid.Theme) layout.Widget {
+	today := time.Now()
+	return wid.List(th, wid.Occupy,
+		wid.Label(th, "Pickers", wid.Heading(), wid.Middle()),
+		wid.Label(th, "Dates", wid.Large()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.DatePicker(th, &birthday, wid.Lbl("Birthday"), wid.DateLayout("02.01.2006"), wid.MaxDate(today)),
+			wid.DatePicker(th, &meeting, wid.Lbl("Meeting"), wid.MinDate(today), wid.DisabledDays(wid.Weekend),
+				wid.Do(onDate)),
+		),
+		wid.DateRangePicker(th, &vacation, &vacEnd, wid.FilledField, wid.Lbl("Vacation"), wid.MinDate(today)),
+		wid.Label(th, &status),
+		wid.Label(th, "Times", wid.Large()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.TimePicker(th, &meeting, wid.Lbl("Meeting time"), wid.Do(onDate)),
+			wid.TimePicker(th, &alarm, wid.Lbl("Alarm"), wid.Clock12()),
+			wid.TimePicker(th, &lapTime, wid.Lbl("Lap time"), wid.Seconds()),
+		),
+		wid.Label(th, "Colors", wid.Large()),
+		wid.Row(th, nil, wid.SpaceClose,
+			wid.ColorPicker(th, &picked, wid.RecentColors()),
+			wid.Label(th, "Selected color", wid.Bg(&picked), wid.Fg(&wid.White)),
+		),
+	)
+}
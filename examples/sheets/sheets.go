@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates bottom sheets and side sheets in gio-v.
+// The standard bottom sheet below the page has details, and is resized by dragging its handle.
+// The modal sheets slide in on top of the page, and are closed by Escape or a click outside.
+
+import (
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+)
+
+var (
+	theme    *wid.Theme
+	form     layout.Widget
+	win      app.Window
+	status   = "Drag the handle of the sheet below, or open a modal sheet"
+	onlyNew  bool
+	withFile bool
+	sortBy   = "Date"
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v sheets"), app.Size(unit.Dp(700), unit.Dp(600)))
+	form = sheets(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func setStatus(s string) {
+	wid.GuiLock.Lock()
+	status = s
+	wid.GuiLock.Unlock()
+	wid.Invalidate()
+}
+
+// share is a modal bottom sheet with actions. Each action closes the sheet.
+func share(th *wid.Theme) layout.Widget {
+	action := func(s string) layout.Widget {
+		return wid.TextButton(th, s, wid.Do(func() {
+			setStatus(s + " clicked")
+			wid.Hide()
+		}))
+	}
+	return wid.ModalBottomSheet(th,
+		wid.Label(th, "Share", wid.Heading()),
+		action("Copy link"),
+		action("Send by mail"),
+		action("Print"),
+	)
+}
+
+// filters is a modal side sheet changing the filters of the page
+func filters(th *wid.Theme) layout.Widget {
+	return wid.SideSheet(th, "Filters",
+		wid.Checkbox(th, "Only new messages", wid.Bool(&onlyNew)),
+		wid.Checkbox(th, "With attachments", wid.Bool(&withFile)),
+		wid.Label(th, "Sort by", wid.Heading()),
+		wid.RadioButton(th, &sortBy, "Date", "Date"),
+		wid.RadioButton(th, &sortBy, "Sender", "Sender"),
+		wid.RadioButton(th, &sortBy, "Subject", "Subject"),
+	)
+}
+
+func details(th *wid.Theme) layout.Widget {
+	return wid.Col(wid.SpaceClose,
+		wid.Label(th, "Details", wid.Heading()),
+		wid.Label(th, "From: Ola Nordmann"),
+		wid.Label(th, "Subject: Meeting tomorrow"),
+		wid.Label(th, "Received: 10:42"),
+		wid.Label(th, "The meeting is moved to room 4, at the same time as before."),
+	)
+}
+
+func sheets(th *wid.Theme) layout.Widget {
+	shareSheet, filterSheet := share(th), filters(th)
+	page := wid.Col(wid.SpaceClose,
+		wid.Label(th, "Sheets", wid.Heading(), wid.Middle()),
+		wid.Label(th, &status, wid.Middle()),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Button(th, "Share", wid.Do(func() { wid.Show(shareSheet) })),
+			wid.Button(th, "Filters", wid.Do(func() { wid.Show(filterSheet) })),
+		),
+		wid.Row(th, nil, wid.SpaceDistribute,
+			wid.Checkbox(th, "Only new", wid.Bool(&onlyNew)),
+			wid.Checkbox(th, "Attachments", wid.Bool(&withFile)),
+			wid.Label(th, &sortBy),
+		),
+	)
+	return wid.BottomSheet(th, page, details(th), wid.Peek(110))
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates tabs in gio-v.
+
+import (
+	"fmt"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+var (
+	theme     *wid.Theme
+	form      layout.Widget
+	win       app.Window
+	page      int
+	section   int
+	document  int
+	documents []wid.Tab
+	count     int
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v tabs"), app.Size(unit.Dp(800), unit.Dp(600)))
+	form = tabs(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func icon(data []byte) *wid.Icon {
+	ic, _ := wid.NewIcon(data)
+	return ic
+}
+
+// newDocument adds a tab, and selects it
+func newDocument() {
+	count++
+	s := fmt.Sprintf("Document %d", count)
+	documents = append(documents, wid.Tab{Text: s, Content: wid.Label(theme, "This is the content of "+s)})
+	document = len(documents) - 1
+}
+
+// closeDocument removes a tab
+func closeDocument(i int) {
+	documents = append(documents[:i], documents[i+1:]...)
+	if document > i || document == len(documents) {
+		document--
+	}
+}
+
+func tabs(th *wid.Theme) layout.Widget {
+	documents = nil
+	count = 0
+	for i := 0; i < 3; i++ {
+		newDocument()
+	}
+	details := wid.Tabs(th, &section, []wid.Tab{
+		{Text: "Overview", Icon: icon(icons.ActionInfo), Content: wid.Label(th, "Secondary tabs are used within a page.")},
+		{Text: "Specifications", Icon: icon(icons.ActionList), Content: wid.Label(th, "The indicator spans the whole tab.")},
+		{Text: "Reviews", Icon: icon(icons.ActionGrade), Content: wid.Label(th, "No reviews yet.")},
+	}, wid.SecondaryTabs())
+	docs := wid.List(th, wid.Occupy,
+		wid.Label(th, "Closable tabs. They can be scrolled when there are many of them.", wid.Large()),
+		wid.Tabs(th, &document, &documents, wid.OnClose(closeDocument)),
+		wid.OutlineButton(th, "Add document", wid.Do(newDocument)),
+	)
+	return wid.Col(wid.SpaceClose,
+		wid.Label(th, "Tabs", wid.Heading(), wid.Middle()),
+		wid.Tabs(th, &page, []wid.Tab{
+			{Text: "Home", Icon: icon(icons.ActionHome), Content: details},
+			{Text: "Documents", Icon: icon(icons.ActionDescription), Content: docs},
+			{Text: "Settings", Icon: icon(icons.ActionSettings), Content: wid.Label(th, "Use the arrow keys to select a tab when the tab row has focus.")},
+		}),
+	)
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// A Gio program that demonstrates a toolbar and a status bar in gio-v.
+// Make the window narrow to see the tools move into the more menu.
+
+import (
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/jkvatne/gio-v/wid"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+var (
+	theme    *wid.Theme
+	form     layout.Widget
+	win      app.Window
+	status   = "Ready"
+	text     = "Some text to edit"
+	bold     bool
+	italic   bool
+	size     = 1
+	saving   bool
+	progress float32
+)
+
+func main() {
+	theme = wid.NewTheme(gofont.Collection(), 16)
+	win.Option(app.Title("Gio-v toolbar"), app.Size(unit.Dp(900), unit.Dp(500)))
+	form = toolbar(theme)
+	go wid.Run(&win, &form, theme)
+	app.Main()
+}
+
+func icon(data []byte) *wid.Icon {
+	ic, _ := wid.NewIcon(data)
+	return ic
+}
+
+// do returns a function that shows the command in the status bar
+func do(s string) func() {
+	return func() {
+		status = s
+	}
+}
+
+// save simulates a slow save, showing the progress in the status bar
+func save() {
+	if saving {
+		return
+	}
+	saving, status = true, "Saving..."
+	go func() {
+		for i := 0; i <= 20; i++ {
+			wid.GuiLock.Lock()
+			progress = float32(i) / 20
+			wid.GuiLock.Unlock()
+			wid.Invalidate()
+			time.Sleep(100 * time.Millisecond)
+		}
+		wid.GuiLock.Lock()
+		saving, status, progress = false, "Saved", 0
+		wid.GuiLock.Unlock()
+		wid.Invalidate()
+	}()
+}
+
+func toolbar(th *wid.Theme) layout.Widget {
+	return wid.Col(wid.SpaceClose,
+		wid.Toolbar(th,
+			wid.ToolButton(th, icon(icons.ActionNoteAdd), "New", wid.Do(do("New"))),
+			wid.ToolButton(th, icon(icons.FileFolderOpen), "Open", wid.Do(do("Open"))),
+			wid.ToolButton(th, icon(icons.ContentSave), "Save", wid.Do(save), wid.En(&saving)),
+			wid.ToolSeparator(th),
+			wid.ToolToggle(th, icon(icons.EditorFormatBold), "Bold", &bold),
+			wid.ToolToggle(th, icon(icons.EditorFormatItalic), "Italic", &italic),
+			wid.ToolSeparator(th),
+			wid.ToolButton(th, icon(icons.ContentContentCut), "Cut", wid.Do(do("Cut"))),
+			wid.ToolButton(th, icon(icons.ContentContentCopy), "Copy", wid.Do(do("Copy"))),
+			wid.ToolButton(th, icon(icons.ContentContentPaste), "Paste", wid.Do(do("Paste"))),
+			wid.ToolSeparator(th),
+			wid.ToolDropDown(th, &size, []string{"Small", "Medium", "Large"}, "Text size"),
+		),
+		wid.Label(th, "Toolbar and status bar", wid.Heading(), wid.Middle()),
+		wid.Edit(th, &text, wid.Lbl("Text")),
+		wid.Label(th, "Make the window narrow to move tools into the more menu."),
+		wid.StatusBar(th,
+			wid.StatusLeft(wid.Label(th, &status)),
+			wid.StatusCenter(wid.ProgressBar(th, &progress, wid.W(200), wid.Thick(6))),
+			wid.StatusRight(
+				wid.Indicator(th, "Bold", &bold),
+				wid.Indicator(th, "Italic", &italic, wid.Role(wid.Tertiary)),
+			),
+		),
+	)
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/font"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// AppBarStyle is the size of a top app bar
+type AppBarStyle int
+
+const (
+	// SmallAppBar has the title to the left of the actions
+	SmallAppBar AppBarStyle = iota
+	// CenterAppBar has the title centered in the bar
+	CenterAppBar
+	// MediumAppBar has a larger title below the actions, that is hidden when the content is scrolled
+	MediumAppBar
+	// LargeAppBar is like MediumAppBar, with an even larger title
+	LargeAppBar
+)
+
+// AppBarDef is a bar at the top of a window or page, with a navigation icon, a title and
+// actions, above the content.
+type AppBarDef struct {
+	Base
+	title   string
+	content layout.Widget
+	style   AppBarStyle
+	nav     *ButtonDef
+	tools   []*ToolDef
+	actions *ToolbarDef
+	// collapse is the number of pixels the bar is collapsed from its expanded height
+	collapse int
+	// pos is the scroll position of the list in the content, 0 when at the top
+	pos *int
+}
+
+// AppBarOption is options specific to top app bars
+type AppBarOption func(*AppBarDef)
+
+// BarStyle is an option giving the size of a top app bar. The default is SmallAppBar.
+func BarStyle(s AppBarStyle) AppBarOption {
+	return func(b *AppBarDef) {
+		b.style = s
+	}
+}
+
+// NavIcon is an option giving a top app bar a navigation icon at the left end, typically a menu
+// or an arrow back. The function do is called when it is clicked.
+func NavIcon(icon *Icon, do func()) AppBarOption {
+	return func(b *AppBarDef) {
+		b.nav = toolButton(b.th, icon, "Navigation", []Option{Do(do)})
+	}
+}
+
+// Actions is an option giving the tools at the right end of a top app bar. They are typically made
+// by ToolButton and ToolToggle. The actions that do not fit are found in an overflow menu.
+func Actions(tools ...*ToolDef) AppBarOption {
+	return func(b *AppBarDef) {
+		b.tools = tools
+	}
+}
+
+// ScrollPos is an option binding a top app bar to the list in its content that reports its
+// scroll position to pos, like a list made by ScrollList.
+func ScrollPos(pos *int) AppBarOption {
+	return func(b *AppBarDef) {
+		b.pos = pos
+	}
+}
+
+func (o AppBarOption) apply(cfg interface{}) {
+	o(cfg.(*AppBarDef))
+}
+
+// TopAppBar returns a top app bar above the content. When it is bound to a list by the ScrollPos
+// option, the bar gets a SurfaceContainer color and a shadow when the list is scrolled. Medium and
+// large bars collapse to the small height when the content is scrolled down, and expand again when
+// the list is back at the top.
+func TopAppBar(th *Theme, title string, content layout.Widget, options ...Option) layout.Widget {
+	b := &AppBarDef{
+		Base: Base{
+			th:        th,
+			role:      Surface,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		title:   title,
+		content: content,
+	}
+	for _, option := range options {
+		option.apply(b)
+	}
+	b.actions = newToolbar(th, b.tools)
+	b.actions.bgColor = &transparent
+	b.actions.padding = layout.Inset{Right: 4}
+	b.actions.right = true
+	return b.Layout
+}
+
+// expanded returns the height of the bar when it is not collapsed
+func (b *AppBarDef) expanded() unit.Dp {
+	switch b.style {
+	case MediumAppBar:
+		return 112
+	case LargeAppBar:
+		return 152
+	}
+	return 64
+}
+
+// handleScroll collapses the bar before the list is scrolled down, and expands it
+// after the list is scrolled back to the top. The rest of the scroll is used by the list.
+func (b *AppBarDef) handleScroll(gtx C, maxCollapse int) {
+	b.collapse = Clamp(b.collapse, 0, maxCollapse)
+	for {
+		r := pointer.ScrollRange{Max: maxCollapse - b.collapse}
+		if b.scrolled() == 0 {
+			r.Min = -b.collapse
+		}
+		ev, ok := gtx.Event(pointer.Filter{Target: b, Kinds: pointer.Scroll, ScrollY: r})
+		if !ok {
+			break
+		}
+		if e, ok := ev.(pointer.Event); ok && e.Kind == pointer.Scroll {
+			b.collapse = Clamp(b.collapse+int(math.Round(float64(e.Scroll.Y))), 0, maxCollapse)
+		}
+	}
+}
+
+// scrolled returns the scroll position of the bound list, or 0 without one
+func (b *AppBarDef) scrolled() int {
+	if b.pos == nil {
+		return 0
+	}
+	return *b.pos
+}
+
+// label returns the recorded title with the given size and alpha
+func (b *AppBarDef) label(gtx C, scale float64, alpha uint8) (op.CallOp, D) {
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: MulAlpha(b.Fg(), alpha)}.Add(gtx.Ops)
+	col := m.Stop()
+	f := *b.Font
+	if b.style == SmallAppBar || b.style == CenterAppBar {
+		f.Weight = font.Medium
+	}
+	m = op.Record(gtx.Ops)
+	d := widget.Label{MaxLines: 1}.Layout(c, b.th.Shaper, f, b.th.TextSize*unit.Sp(b.FontScale*scale), b.title, col)
+	return m.Stop(), d
+}
+
+// Layout draws the content below the bar, and then the bar
+func (b *AppBarDef) Layout(gtx C) D {
+	size := gtx.Constraints.Max
+	rowH := Px(gtx, unit.Dp(64))
+	maxCollapse := Max(0, Px(gtx, b.expanded())-rowH)
+	b.handleScroll(gtx, maxCollapse)
+	barH := rowH + maxCollapse - b.collapse
+
+	// The content is drawn first, so the shadow of the bar falls on it
+	c := gtx
+	c.Constraints = layout.Exact(image.Pt(size.X, Max(0, size.Y-barH)))
+	o := op.Offset(image.Pt(0, barH)).Push(gtx.Ops)
+	cl := clip.Rect{Max: c.Constraints.Max}.Push(gtx.Ops)
+	b.content(c)
+	// The bar gets the scroll events before the list, and passes on what it does not use
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	event.Op(gtx.Ops, b)
+	pass.Pop()
+	cl.Pop()
+	o.Pop()
+
+	bar := image.Rect(0, 0, size.X, barH)
+	bg := b.Bg()
+	if b.scrolled() > 0 || maxCollapse > 0 && b.collapse == maxCollapse {
+		DrawShadow(gtx, bar, 0, Px(gtx, unit.Dp(3)))
+		bg = b.th.Bg[SurfaceContainer]
+	}
+	paint.FillShape(gtx.Ops, bg, clip.Rect(bar).Op())
+	cl = clip.Rect(bar).Push(gtx.Ops)
+	defer cl.Pop()
+
+	// The navigation icon
+	x := Px(gtx, unit.Dp(16))
+	if b.nav != nil {
+		m := op.Record(gtx.Ops)
+		d := b.nav.Layout(gtx)
+		call := m.Stop()
+		left := Px(gtx, unit.Dp(4))
+		o := op.Offset(image.Pt(left, (rowH-d.Size.Y)/2)).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		o.Pop()
+		x = left + d.Size.X + Px(gtx, unit.Dp(8))
+	}
+
+	// The small title is faded in when a medium or large bar collapses
+	frac := float32(1)
+	if maxCollapse > 0 {
+		frac = float32(b.collapse) / float32(maxCollapse)
+	}
+	titleCall, titleD := b.label(gtx, 1.4, uint8(255*frac))
+	gap := Px(gtx, unit.Dp(16))
+
+	// The actions use the space not needed by the title
+	c = gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(Max(0, size.X-x-titleD.Size.X-gap), rowH)}
+	if b.style == CenterAppBar {
+		c.Constraints.Max.X = Max(0, (size.X-titleD.Size.X)/2-gap)
+	}
+	m := op.Record(gtx.Ops)
+	d := b.actions.Layout(c)
+	call := m.Stop()
+	actionsX := size.X - d.Size.X
+	o = op.Offset(image.Pt(actionsX, (rowH-d.Size.Y)/2)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+
+	if frac > 0 {
+		tx := x
+		if b.style == CenterAppBar {
+			tx = Clamp((size.X-titleD.Size.X)/2, x, Max(x, actionsX-titleD.Size.X))
+		}
+		o := op.Offset(image.Pt(tx, (rowH-titleD.Size.Y)/2)).Push(gtx.Ops)
+		titleCall.Add(gtx.Ops)
+		o.Pop()
+	}
+
+	// The large title is below the row, and fades out as the bar collapses
+	if maxCollapse > 0 && frac < 1 {
+		scale, pad := 1.7, unit.Dp(24)
+		if b.style == LargeAppBar {
+			scale, pad = 2.0, unit.Dp(28)
+		}
+		call, d := b.label(gtx, scale, uint8(255*(1-frac)))
+		o := op.Offset(image.Pt(Px(gtx, unit.Dp(16)), barH-Px(gtx, pad)-d.Size.Y)).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		o.Pop()
+	}
+	return D{Size: size}
+}
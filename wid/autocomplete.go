@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// suggestions is the state of the autocomplete popup of an Edit
+type suggestions struct {
+	provider func(prefix string) []string
+	// mu protects busy, query, found, prefix and fresh, used by the provider goroutine
+	mu     sync.Mutex
+	busy   bool
+	query  string
+	found  []string
+	prefix string
+	fresh  bool
+	// The rest is only used in the gui goroutine
+	items    []string
+	visible  bool
+	focused  bool
+	selected int
+	accepted string
+	clicks   []gesture.Click
+	list     layout.List
+}
+
+// request asks for new suggestions for the given text. The provider is called in a
+// separate goroutine. If it is busy, the latest request is run when it is finished.
+func (s *suggestions) request(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.query = text
+	if !s.busy {
+		s.busy = true
+		go s.run(text)
+	}
+}
+
+// run calls the provider until it has found suggestions for the last query.
+func (s *suggestions) run(text string) {
+	for {
+		found := s.provider(text)
+		s.mu.Lock()
+		if text == s.query {
+			s.found, s.prefix, s.fresh, s.busy = found, text, true, false
+			s.mu.Unlock()
+			Invalidate()
+			return
+		}
+		text = s.query
+		s.mu.Unlock()
+	}
+}
+
+// textChanged is called when the user has modified the text.
+func (s *suggestions) textChanged(text string) {
+	if text == s.accepted || !s.focused {
+		return
+	}
+	s.accepted = ""
+	if text == "" {
+		s.visible = false
+		return
+	}
+	s.request(text)
+}
+
+// handleSuggestions takes new suggestions from the provider, and handles keys and
+// clicks in the popup. It must be called before the editor gets its events.
+func (e *EditDef) handleSuggestions(gtx C) {
+	s := e.suggest
+	if s == nil {
+		return
+	}
+	s.focused = gtx.Focused(&e.Editor)
+	text := e.Text()
+	s.mu.Lock()
+	if s.fresh {
+		s.fresh = false
+		// Suggestions for an old text are dropped
+		if s.prefix == text && s.focused {
+			s.items = s.found
+			s.selected = 0
+			s.list.Position = layout.Position{}
+			s.visible = len(s.items) > 0 && !(len(s.items) == 1 && s.items[0] == text)
+		}
+	}
+	s.mu.Unlock()
+	if !s.focused {
+		s.visible = false
+	}
+	for i := range s.clicks {
+		for {
+			ev, ok := s.clicks[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind == gesture.KindClick && s.visible && i < len(s.items) {
+				e.acceptSuggestion(gtx, s.items[i])
+			}
+		}
+	}
+	if !s.visible {
+		return
+	}
+	n := len(s.items)
+	// These keys are taken before the editor gets them, while the popup is shown
+	filters := []event.Filter{
+		key.Filter{Focus: &e.Editor, Name: key.NameUpArrow},
+		key.Filter{Focus: &e.Editor, Name: key.NameDownArrow},
+		key.Filter{Focus: &e.Editor, Name: key.NameReturn},
+		key.Filter{Focus: &e.Editor, Name: key.NameEnter},
+		key.Filter{Focus: &e.Editor, Name: key.NameEscape},
+	}
+	if e.ghostText() != "" {
+		filters = append(filters, key.Filter{Focus: &e.Editor, Name: key.NameRightArrow})
+	}
+	for {
+		ev, ok := gtx.Event(filters...)
+		if !ok {
+			break
+		}
+		ke, ok := ev.(key.Event)
+		if !ok || ke.State != key.Press || !s.visible {
+			continue
+		}
+		switch ke.Name {
+		case key.NameDownArrow:
+			s.selected = (s.selected + 1) % n
+		case key.NameUpArrow:
+			s.selected = (s.selected + n - 1) % n
+		case key.NameReturn, key.NameEnter, key.NameRightArrow:
+			e.acceptSuggestion(gtx, s.items[s.selected])
+		case key.NameEscape:
+			s.visible = false
+		}
+	}
+	// Keep the selected item inside the visible part of the list
+	if s.selected < s.list.Position.First {
+		s.list.Position.First, s.list.Position.Offset = s.selected, 0
+	} else if c := s.list.Position.Count; c > 0 && s.selected >= s.list.Position.First+c {
+		s.list.Position.First, s.list.Position.Offset = s.selected-c+1, 0
+	}
+}
+
+// acceptSuggestion replaces the text with the suggestion, and closes the popup.
+func (e *EditDef) acceptSuggestion(gtx C, text string) {
+	s := e.suggest
+	s.accepted = text
+	s.visible = false
+	e.SetText(text)
+	n := utf8.RuneCountInString(text)
+	e.SetCaret(n, n)
+	// Report the change at once, without debouncing
+	e.changePending = true
+	e.changeAt = gtx.Now
+}
+
+// ghostText returns the part of the selected suggestion that is not yet typed.
+// It is empty unless the caret is at the end of the text.
+func (e *EditDef) ghostText() string {
+	s := e.suggest
+	if s == nil || !s.visible || s.selected >= len(s.items) || e.Mask != 0 {
+		return ""
+	}
+	text := e.Text()
+	start, end := e.Selection()
+	n := utf8.RuneCountInString(text)
+	if start != n || end != n {
+		return ""
+	}
+	item := []rune(s.items[s.selected])
+	if len(item) <= n || !strings.EqualFold(string(item[:n]), text) {
+		return ""
+	}
+	return string(item[n:])
+}
+
+// layoutGhost draws the rest of the selected suggestion after the text. The
+// gtx must be the one used for laying out the editor.
+func (e *EditDef) layoutGhost(gtx C, size unit.Sp) {
+	ghost := e.ghostText()
+	if ghost == "" {
+		return
+	}
+	_, dims := e.recordText(gtx, e.Text(), size, e.Fg())
+	if dims.Size.X >= gtx.Constraints.Max.X {
+		return
+	}
+	call, _ := e.recordText(gtx, ghost, size, MulAlpha(e.Fg(), 110))
+	defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
+	defer op.Offset(image.Pt(dims.Size.X, 0)).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+// layoutSuggestions draws the popup list just below the given box. Like the
+// dropdown list, it is deferred so that it is drawn on top of other widgets.
+func (e *EditDef) layoutSuggestions(gtx C, box image.Rectangle) {
+	s := e.suggest
+	if s == nil || !s.visible {
+		return
+	}
+	for len(s.clicks) < len(s.items) {
+		s.clicks = append(s.clicks, gesture.Click{})
+	}
+	s.list.Axis = layout.Vertical
+	macro := op.Record(gtx.Ops)
+	o := op.Offset(image.Pt(box.Min.X, box.Max.Y)).Push(gtx.Ops)
+	c := gtx
+	c.Constraints.Min = image.Pt(box.Dx(), 0)
+	// Limit list length to 8 times the height of the edit, like the dropdown
+	c.Constraints.Max = image.Pt(box.Dx(), box.Dy()*8)
+	listMacro := op.Record(gtx.Ops)
+	dims := s.list.Layout(c, len(s.items), e.suggestionItem)
+	listCall := listMacro.Stop()
+	rect := image.Rectangle{Max: image.Pt(box.Dx(), dims.Size.Y)}
+	cl := clip.Rect(rect).Push(gtx.Ops)
+	paint.Fill(gtx.Ops, e.th.Bg[Canvas])
+	listCall.Add(gtx.Ops)
+	cl.Pop()
+	paintBorder(gtx, rect, e.th.Fg[Outline], float32(Px(gtx, e.borderThickness)), 0)
+	o.Pop()
+	op.Defer(gtx.Ops, macro.Stop())
+}
+
+// suggestionItem draws one line in the popup list
+func (e *EditDef) suggestionItem(gtx C, i int) D {
+	s := e.suggest
+	fg := e.th.Fg[Canvas]
+	textSize := e.th.TextSize * unit.Sp(e.FontScale)
+	call, dims := e.recordText(gtx, s.items[i], textSize, fg)
+	pad := Px(gtx, unit.Dp(4))
+	padX := Px(gtx, unit.Dp(textSize*0.4))
+	size := image.Pt(gtx.Constraints.Min.X, dims.Size.Y+2*pad)
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	if i == s.selected {
+		paint.Fill(gtx.Ops, MulAlpha(fg, 64))
+	} else if s.clicks[i].Hovered() {
+		paint.Fill(gtx.Ops, MulAlpha(fg, 24))
+	}
+	o := op.Offset(image.Pt(padX, pad)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+	s.clicks[i].Add(gtx.Ops)
+	pointer.CursorPointer.Add(gtx.Ops)
+	return D{Size: size}
+}
+
+// Suggest is an option that shows a popup with suggestions below the edit while typing.
+// The provider is called with the text typed so far, and is run in a separate
+// goroutine, so it can be slow. Use arrow keys to select and Enter to accept.
+func Suggest(provider func(prefix string) []string) EditOption {
+	return func(e *EditDef) {
+		e.suggest = &suggestions{provider: provider}
+	}
+}
+
+// SuggestFrom returns a suggestion provider giving all the items that
+// start with the prefix, ignoring case.
+func SuggestFrom(items []string) func(prefix string) []string {
+	return func(prefix string) []string {
+		var found []string
+		for _, s := range items {
+			if strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix)) {
+				found = append(found, s)
+			}
+		}
+		return found
+	}
+}
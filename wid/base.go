@@ -59,6 +59,8 @@ type Base struct {
 	FontScale    float64
 	DpNo         *int
 	Alignment    text.Alignment
+	// untracked widgets do not record their changes in the undo history
+	untracked bool
 }
 
 // Fg returns the foreground color of a widget, either from
@@ -82,7 +84,8 @@ func (wid *Base) Bg() color.NRGBA {
 }
 
 // CheckDisabler is used when a variable controls the disabling of a widget.
-func (wid *Base) CheckDisabler(gtx C) {
+// It returns the context, disabled when the variable is true.
+func (wid *Base) CheckDisabler(gtx C) C {
 	if wid.disabler != nil {
 		GuiLock.RLock()
 		if *wid.disabler {
@@ -90,6 +93,7 @@ func (wid *Base) CheckDisabler(gtx C) {
 		}
 		GuiLock.RUnlock()
 	}
+	return gtx
 }
 
 // UpdateMousePos must be called from the main program in order to get mouse
@@ -163,7 +167,7 @@ func Run(win *app.Window, mainForm *layout.Widget, th *Theme) {
 			paint.PaintOp{}.Add(gtx.Ops)
 			// Disable main form if a dialog is shown
 			ctx := gtx
-			if dialog != nil {
+			if dialogShown() {
 				ctx = gtx.Disabled()
 			}
 			// Catch mouse position from current event. This is a hack to fetch mouse
@@ -171,10 +175,16 @@ func Run(win *app.Window, mainForm *layout.Widget, th *Theme) {
 			UpdateMousePos(gtx, win)
 			// Call all the widgets in the current form
 			(*mainForm)(ctx)
-			// Draw dialog (if any exist) on top of the current form
-			if dialog != nil {
-				dialog(gtx)
-			}
+			// Draw the dialogs (if any exist) on top of the current form
+			layoutDialogs(gtx)
+			// Notifications are shown on top of the form and dialog
+			layoutSnackbars(gtx, th)
+			// Shortcuts not used by the focused widget, in the form or dialog shown
+			handleShortcuts(gtx)
+			// Ctrl+Z/Ctrl+Y not used by the focused widget or a shortcut will undo/redo form changes
+			handleUndoKeys(ctx)
+			// Enter and Escape not used otherwise click the buttons of the dialog on top
+			handleDialogKeys(gtx)
 			// Signal the library to do the actual drawing
 			e.Frame(gtx.Ops)
 
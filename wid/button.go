@@ -115,7 +115,7 @@ func aButton[V StrValue](style ButtonStyle, th *Theme, label V, options ...Optio
 func (b *ButtonDef) Layout(gtx C) D {
 	mt, mb, ml, mr := ScaleInset(gtx, b.margin)
 	pt, pb, pl, pr := ScaleInset(gtx, b.padding)
-	b.CheckDisabler(gtx)
+	gtx = b.CheckDisabler(gtx)
 	// Move the whole button down/right margin offset
 	defer op.Offset(image.Pt(ml, mt)).Push(gtx.Ops).Pop()
 	// Handle clickable pointer/keyboard inputs
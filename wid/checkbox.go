@@ -82,9 +82,13 @@ func (c *CheckBoxDef) Layout(gtx C) D {
 		c.Checked = !c.Checked
 		GuiLock.Lock()
 		if c.BoolValue != nil {
+			old := *c.BoolValue
 			*c.BoolValue = c.Checked
+			c.record(c.BoolValue, old)
 		} else if c.StrValue != nil {
+			old := *c.StrValue
 			*c.StrValue = c.Key
+			c.record(c.StrValue, old)
 		}
 		GuiLock.Unlock()
 		if c.onUserChange != nil {
@@ -30,6 +30,8 @@ type Clickable struct {
 	ClickMovesFocus bool
 	index           *int
 	maxIndex        int
+	// noUndo is set for an untracked dropdown, so index changes are not recorded
+	noUndo bool
 }
 
 // Click represents a click.
@@ -129,17 +131,24 @@ func (b *Clickable) HandleEvents(gtx C) {
 		}
 	}
 
-	for {
-		e, ok := gtx.Event(
-			key.FocusFilter{Target: b},
-			key.Filter{Focus: b, Name: key.NameReturn},
-			key.Filter{Focus: b, Name: key.NameSpace},
+	filters := []event.Filter{
+		key.FocusFilter{Target: b},
+		key.Filter{Focus: b, Name: key.NameReturn},
+		key.Filter{Focus: b, Name: key.NameSpace},
+	}
+	if b.index != nil {
+		// Dropdowns also use the arrows to select, and Escape to close the list.
+		// Buttons leave Escape to the dialog they are in.
+		filters = append(filters,
 			key.Filter{Focus: b, Name: key.NameDownArrow},
 			key.Filter{Focus: b, Name: key.NameUpArrow},
 			key.Filter{Focus: b, Name: key.NameLeftArrow},
 			key.Filter{Focus: b, Name: key.NameRightArrow},
 			key.Filter{Focus: b, Name: key.NameEscape},
 		)
+	}
+	for {
+		e, ok := gtx.Event(filters...)
 		if !ok {
 			break
 		}
@@ -160,13 +169,20 @@ func (b *Clickable) HandleEvents(gtx C) {
 				} else if e.Name == key.NameDownArrow || e.Name == key.NameRightArrow {
 					GuiLock.Lock()
 					*b.index++
+					if !b.noUndo {
+						recordChange(b.index, *b.index-1)
+					}
 					GuiLock.Unlock()
 				} else if e.Name == key.NameUpArrow || e.Name == key.NameLeftArrow {
 					GuiLock.Lock()
+					old := *b.index
 					*b.index--
 					if *b.index < 0 {
 						*b.index = 0
 					}
+					if !b.noUndo {
+						recordChange(b.index, old)
+					}
 					GuiLock.Unlock()
 				}
 			}
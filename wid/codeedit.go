@@ -0,0 +1,782 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gioui.org/font"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// CodeEditDef is a multi-line editor for source code and configuration files.
+type CodeEditDef struct {
+	Base
+	ed        widget.Editor
+	value     *string
+	tokenizer Tokenizer
+	tabWidth  int
+	lines     int
+	// synced is the last value read from or written to the bound variable, and shown
+	// is the text given to the editor, with the tabs expanded
+	synced string
+	shown  string
+	// valueLines are the lines of the value, used when the text is written back, and tabLines
+	// are the lines with tabs, keyed by the expanded line. tabIndent is true when the value
+	// uses tabs for indentation.
+	valueLines []string
+	tabLines   map[string]string
+	tabIndent  bool
+	// text is the editor text, split into runes, lines and segments of the same token kind
+	text       string
+	runes      []rune
+	lineStarts []int
+	segments   []codeSegment
+	regions    []widget.Region
+	// The find/replace bar
+	findOpen    bool
+	replaceOpen bool
+	find        string
+	replace     string
+	findEdit    EditDef
+	replaceEdit EditDef
+	buttons     []layout.Widget
+	matches     []int
+}
+
+// codeSegment is a part of the text with the same token kind. Start and end are rune offsets.
+type codeSegment struct {
+	start, end int
+	kind       TokenKind
+}
+
+// CodeOption is options specific to CodeEdit
+type CodeOption func(*CodeEditDef)
+
+// Tokens is an option setting the tokenizer used for syntax highlighting,
+// like GoTokens, JSONTokens or YAMLTokens.
+func Tokens(t Tokenizer) CodeOption {
+	return func(c *CodeEditDef) {
+		c.tokenizer = t
+	}
+}
+
+// TabWidth is an option setting the number of spaces used for indentation. Default is 4.
+func TabWidth(n int) CodeOption {
+	return func(c *CodeEditDef) {
+		c.tabWidth = n
+	}
+}
+
+// CodeLines is an option setting the height of the editor in lines.
+// By default, it fills the available height, or 20 lines in a list.
+func CodeLines(n int) CodeOption {
+	return func(c *CodeEditDef) {
+		c.lines = n
+	}
+}
+
+func (o CodeOption) apply(cfg interface{}) {
+	o(cfg.(*CodeEditDef))
+}
+
+// CodeEdit returns an editor for source code, with line numbers and syntax highlighting.
+// Return keeps the indentation, Tab and Shift+Tab indents and unindents the selected lines,
+// and Ctrl+F and Ctrl+H opens the find/replace bar.
+// Tabs in the text are shown as spaces. The lines are written back with their tabs, and
+// indentation typed by the user is written with tabs when the text is indented with tabs.
+func CodeEdit(th *Theme, value *string, options ...Option) layout.Widget {
+	c := &CodeEditDef{
+		Base: Base{
+			th:        th,
+			role:      Canvas,
+			padding:   th.DefaultPadding,
+			margin:    th.DefaultMargin,
+			Font:      &font.Font{Typeface: monoTypeface},
+			FontScale: 1.0,
+		},
+		value:    value,
+		tabWidth: 4,
+	}
+	c.synced = "\x00"
+	for _, option := range options {
+		option.apply(c)
+	}
+	c.findEdit = barEdit(th, &c.find, "Find")
+	c.findEdit.onSubmit = func(string) { c.findNext(1) }
+	c.replaceEdit = barEdit(th, &c.replace, "Replace")
+	c.replaceEdit.onSubmit = func(string) { c.replaceOne() }
+	c.buttons = []layout.Widget{
+		TextButton(th, "Prev", Do(func() { c.findNext(-1) })),
+		TextButton(th, "Next", Do(func() { c.findNext(1) })),
+		TextButton(th, "Replace", Do(c.replaceOne)),
+		TextButton(th, "All", Do(c.replaceAll)),
+		TextButton(th, "Close", Do(c.closeFind)),
+	}
+	return c.Layout
+}
+
+// barEdit returns an edit for a find/replace bar, writing to v at every keystroke.
+// The text is not form data, so it is not recorded in the undo history.
+func barEdit(th *Theme, v *string, hint string) EditDef {
+	e := DefaultEditDef(th)
+	dp := 0
+	e.DpNo = &dp
+	e.value = v
+	e.hint = hint
+	e.live = true
+	e.untracked = true
+	e.margin = layout.Inset{}
+	return e
+}
+
+// expandTabs replaces tabs by spaces up to the next tab stop
+func expandTabs(s string, width int) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			n := width - col%width
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case '\n':
+			b.WriteRune(r)
+			col = 0
+		default:
+			b.WriteRune(r)
+			col++
+		}
+	}
+	return b.String()
+}
+
+// indentTabs replaces the spaces indenting the line by tabs, up to the last tab stop
+func indentTabs(s string, width int) string {
+	n := len(s) - len(strings.TrimLeft(s, " "))
+	if n < width {
+		return s
+	}
+	return strings.Repeat("\t", n/width) + s[n/width*width:]
+}
+
+// setTabs finds the lines of the value with tabs, used when the text is written back
+func (c *CodeEditDef) setTabs(v string) {
+	c.valueLines, c.tabLines, c.tabIndent = nil, nil, false
+	if !strings.Contains(v, "\t") {
+		return
+	}
+	c.valueLines = strings.Split(v, "\n")
+	c.tabLines = map[string]string{}
+	for _, line := range c.valueLines {
+		if strings.Contains(line, "\t") {
+			if _, ok := c.tabLines[expandTabs(line, c.tabWidth)]; !ok {
+				c.tabLines[expandTabs(line, c.tabWidth)] = line
+			}
+			c.tabIndent = c.tabIndent || line[0] == '\t'
+		}
+	}
+}
+
+// withTabs converts the editor text to the value. Lines that are not changed are written
+// as they were in the value, also when they moved, and lines that are edited or new are
+// indented with tabs if the value was.
+func (c *CodeEditDef) withTabs(text string) string {
+	if c.tabLines == nil {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i < len(c.valueLines) && expandTabs(c.valueLines[i], c.tabWidth) == line {
+			lines[i] = c.valueLines[i]
+		} else if s, ok := c.tabLines[line]; ok {
+			lines[i] = s
+		} else if c.tabIndent && !slices.Contains(c.valueLines, line) {
+			lines[i] = indentTabs(line, c.tabWidth)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tokenize splits the text into lines and segments
+func (c *CodeEditDef) tokenize(text string) {
+	c.text = text
+	c.runes = []rune(text)
+	c.segments = c.segments[:0]
+	c.lineStarts = append(c.lineStarts[:0], 0)
+	for i, r := range c.runes {
+		if r == '\n' {
+			c.lineStarts = append(c.lineStarts, i+1)
+		}
+	}
+	var tokens []Token
+	if c.tokenizer != nil {
+		tokens = c.tokenizer(text)
+	}
+	// Convert byte offsets to rune offsets, filling the gaps with plain text
+	b, r, prev := 0, 0, 0
+	advance := func(to int) int {
+		for b < to && b < len(text) {
+			_, n := utf8.DecodeRuneInString(text[b:])
+			b += n
+			r++
+		}
+		return r
+	}
+	for _, t := range tokens {
+		if t.Start < b || t.End <= t.Start {
+			// Overlapping tokens are ignored
+			continue
+		}
+		s := advance(t.Start)
+		if s > prev {
+			c.segments = append(c.segments, codeSegment{prev, s, TokText})
+		}
+		prev = advance(t.End)
+		c.segments = append(c.segments, codeSegment{s, prev, t.Kind})
+	}
+	if n := len(c.runes); n > prev {
+		c.segments = append(c.segments, codeSegment{prev, n, TokText})
+	}
+}
+
+// kindAt returns the token kind at rune offset i
+func (c *CodeEditDef) kindAt(i int) TokenKind {
+	n := sort.Search(len(c.segments), func(k int) bool { return c.segments[k].end > i })
+	if n < len(c.segments) && c.segments[n].start <= i {
+		return c.segments[n].kind
+	}
+	return TokText
+}
+
+// lineOf returns the line number of rune offset i
+func (c *CodeEditDef) lineOf(i int) int {
+	return sort.SearchInts(c.lineStarts, i+1) - 1
+}
+
+// lineEnd returns the rune offset of the end of line n, excluding the newline
+func (c *CodeEditDef) lineEnd(n int) int {
+	if n+1 < len(c.lineStarts) {
+		return c.lineStarts[n+1] - 1
+	}
+	return len(c.runes)
+}
+
+// tokenColor returns the text color used for a token kind
+func (c *CodeEditDef) tokenColor(k TokenKind) color.NRGBA {
+	switch k {
+	case TokKeyword, TokKey:
+		return c.th.Bg[Primary]
+	case TokType:
+		return c.th.Bg[Secondary]
+	case TokString:
+		return c.th.Bg[Tertiary]
+	case TokNumber, TokLiteral:
+		return Interpolate(c.th.Bg[Tertiary], c.th.Bg[Error], 0.5)
+	case TokComment:
+		return MulAlpha(c.Fg(), 140)
+	}
+	return c.Fg()
+}
+
+// sync updates the editor when the bound variable is changed by the program,
+// and writes the text back when it is edited.
+func (c *CodeEditDef) sync() {
+	GuiLock.RLock()
+	v := *c.value
+	GuiLock.RUnlock()
+	if v != c.synced {
+		// The tabs are only expanded in the editor, and the value is not changed
+		c.shown = expandTabs(v, c.tabWidth)
+		c.ed.SetText(c.shown)
+		c.synced = v
+		c.setTabs(v)
+	}
+	text := c.ed.Text()
+	if text != c.text || c.runes == nil {
+		c.tokenize(text)
+	}
+	if text != c.shown {
+		v = c.withTabs(text)
+		GuiLock.Lock()
+		old := *c.value
+		*c.value = v
+		c.record(c.value, old)
+		GuiLock.Unlock()
+		c.synced, c.shown = v, text
+		c.setTabs(v)
+		if c.onUserChange != nil {
+			c.onUserChange()
+		}
+	}
+}
+
+// handleKeys handles the keys used for indentation and find/replace, before the editor gets them.
+func (c *CodeEditDef) handleKeys(gtx C) {
+	filters := []event.Filter{
+		key.Filter{Focus: &c.ed, Name: key.NameTab, Optional: key.ModShift},
+		key.Filter{Focus: &c.ed, Name: key.NameReturn, Optional: key.ModShift},
+		key.Filter{Focus: &c.ed, Name: key.NameEnter, Optional: key.ModShift},
+		key.Filter{Focus: &c.ed, Name: "F", Required: key.ModShortcut},
+		key.Filter{Focus: &c.ed, Name: "H", Required: key.ModShortcut},
+		key.Filter{Focus: &c.ed, Name: key.NameF3, Optional: key.ModShift},
+		key.Filter{Focus: &c.findEdit.Editor, Name: key.NameF3, Optional: key.ModShift},
+	}
+	if c.findOpen {
+		// Escape closes the bar. When it is closed, Escape is left to a dialog.
+		filters = append(filters,
+			key.Filter{Focus: &c.ed, Name: key.NameEscape},
+			key.Filter{Focus: &c.findEdit.Editor, Name: key.NameEscape},
+			key.Filter{Focus: &c.replaceEdit.Editor, Name: key.NameEscape},
+		)
+	}
+	for {
+		ev, ok := gtx.Event(filters...)
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+		switch e.Name {
+		case key.NameTab:
+			c.indent(!e.Modifiers.Contain(key.ModShift))
+		case key.NameReturn, key.NameEnter:
+			c.newLine()
+		case "F", "H":
+			c.findOpen = true
+			c.replaceOpen = e.Name == "H"
+			if s := c.ed.SelectedText(); s != "" && !strings.Contains(s, "\n") {
+				c.find = s
+			}
+			gtx.Execute(key.FocusCmd{Tag: &c.findEdit.Editor})
+		case key.NameF3:
+			if e.Modifiers.Contain(key.ModShift) {
+				c.findNext(-1)
+			} else {
+				c.findNext(1)
+			}
+		case key.NameEscape:
+			c.closeFind()
+			gtx.Execute(key.FocusCmd{Tag: &c.ed})
+		}
+	}
+}
+
+// newLine inserts a line break, keeping the indentation of the current line.
+// After an opening bracket or a colon, the indentation is increased.
+func (c *CodeEditDef) newLine() {
+	c.tokenize(c.ed.Text())
+	pos, _ := c.ed.Selection()
+	pos = Min(pos, len(c.runes))
+	start := c.lineStarts[c.lineOf(pos)]
+	n := start
+	for n < pos && c.runes[n] == ' ' {
+		n++
+	}
+	indent := strings.Repeat(" ", n-start)
+	before := strings.TrimRight(string(c.runes[start:pos]), " ")
+	if before != "" && strings.ContainsRune("{[(:", rune(before[len(before)-1])) {
+		s := "\n" + indent + strings.Repeat(" ", c.tabWidth)
+		if pos < len(c.runes) && strings.ContainsRune("}])", c.runes[pos]) {
+			// Put the closing bracket on its own line
+			c.ed.Insert(s + "\n" + indent)
+			p := pos + utf8.RuneCountInString(s)
+			c.ed.SetCaret(p, p)
+			return
+		}
+		indent = s[1:]
+	}
+	c.ed.Insert("\n" + indent)
+}
+
+// indent inserts spaces up to the next tab stop, or indents/unindents all selected lines
+func (c *CodeEditDef) indent(in bool) {
+	c.tokenize(c.ed.Text())
+	start, end := c.ed.Selection()
+	if start > end {
+		start, end = end, start
+	}
+	first, last := c.lineOf(start), c.lineOf(end)
+	if in && first == last {
+		col := start - c.lineStarts[first]
+		c.ed.Insert(strings.Repeat(" ", c.tabWidth-col%c.tabWidth))
+		return
+	}
+	if last > first && end == c.lineStarts[last] {
+		// The line after a selection ending at a line start is not changed
+		last--
+	}
+	lines := strings.Split(string(c.runes[c.lineStarts[first]:c.lineEnd(last)]), "\n")
+	for i, l := range lines {
+		if in {
+			lines[i] = strings.Repeat(" ", c.tabWidth) + l
+		} else {
+			n := 0
+			for n < c.tabWidth && n < len(l) && l[n] == ' ' {
+				n++
+			}
+			lines[i] = l[n:]
+		}
+	}
+	s := strings.Join(lines, "\n")
+	c.ed.SetCaret(c.lineStarts[first], c.lineEnd(last))
+	c.ed.Insert(s)
+	c.ed.SetCaret(c.lineStarts[first]+utf8.RuneCountInString(s), c.lineStarts[first])
+}
+
+// findMatches returns the rune offsets of all occurrences of the find text
+func (c *CodeEditDef) findMatches() []int {
+	c.matches = c.matches[:0]
+	f := []rune(c.find)
+	if len(f) == 0 {
+		return c.matches
+	}
+	for i := 0; i+len(f) <= len(c.runes); i++ {
+		if string(c.runes[i:i+len(f)]) == c.find {
+			c.matches = append(c.matches, i)
+			i += len(f) - 1
+		}
+	}
+	return c.matches
+}
+
+// findNext selects the next (dir=1) or previous (dir=-1) match
+func (c *CodeEditDef) findNext(dir int) {
+	c.tokenize(c.ed.Text())
+	m := c.findMatches()
+	if len(m) == 0 {
+		return
+	}
+	start, end := c.ed.Selection()
+	pos := Max(start, end)
+	if dir < 0 {
+		pos = Min(start, end)
+	}
+	i := sort.SearchInts(m, pos)
+	if dir < 0 {
+		i = (i - 1 + len(m)) % len(m)
+	} else {
+		i = i % len(m)
+	}
+	n := utf8.RuneCountInString(c.find)
+	c.ed.SetCaret(m[i]+n, m[i])
+}
+
+// replaceOne replaces the selected match, and selects the next one
+func (c *CodeEditDef) replaceOne() {
+	if c.find != "" && c.ed.SelectedText() == c.find {
+		c.ed.Insert(c.replace)
+	}
+	c.findNext(1)
+}
+
+// replaceAll replaces all matches
+func (c *CodeEditDef) replaceAll() {
+	if c.find == "" {
+		return
+	}
+	text := c.ed.Text()
+	if s := strings.ReplaceAll(text, c.find, c.replace); s != text {
+		c.ed.SetCaret(0, c.ed.Len())
+		c.ed.Insert(s)
+	}
+}
+
+func (c *CodeEditDef) closeFind() {
+	c.findOpen = false
+	c.replaceOpen = false
+}
+
+// matchBracket returns the offsets of the bracket at the caret and the matching bracket
+func (c *CodeEditDef) matchBracket() (int, int) {
+	const open, close = "([{", ")]}"
+	pos, _ := c.ed.Selection()
+	for _, p := range []int{pos - 1, pos} {
+		if p < 0 || p >= len(c.runes) {
+			continue
+		}
+		r := c.runes[p]
+		dir, i := 1, strings.IndexRune(open, r)
+		if i < 0 {
+			dir, i = -1, strings.IndexRune(close, r)
+		}
+		if i < 0 || c.kindAt(p) == TokString || c.kindAt(p) == TokComment {
+			continue
+		}
+		this, other := rune(open[i]), rune(close[i])
+		if dir < 0 {
+			this, other = other, this
+		}
+		depth := 0
+		for k := p; k >= 0 && k < len(c.runes); k += dir {
+			if c.runes[k] != this && c.runes[k] != other {
+				continue
+			}
+			if kind := c.kindAt(k); kind == TokString || kind == TokComment {
+				continue
+			}
+			if c.runes[k] == this {
+				depth++
+			} else if depth--; depth == 0 {
+				return p, k
+			}
+		}
+		return p, -1
+	}
+	return -1, -1
+}
+
+// fillRegions fills the regions of the rune range [start,end), offset by ofs.
+// When fullWidth is true, the regions are extended to the full width w.
+func (c *CodeEditDef) fillRegions(gtx C, start, end int, col color.NRGBA, fullWidth bool, w int) {
+	c.regions = c.ed.Regions(start, end, c.regions)
+	for _, r := range c.regions {
+		b := r.Bounds
+		if fullWidth {
+			b.Min.X, b.Max.X = 0, w
+		}
+		paint.FillShape(gtx.Ops, col, clip.Rect(b).Op())
+	}
+}
+
+// drawText draws the string at the position of the region, with the baseline aligned to the editor text
+func (c *CodeEditDef) drawText(gtx C, s string, r widget.Region, col color.NRGBA, size unit.Sp) {
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	colorCall := m.Stop()
+	m = op.Record(gtx.Ops)
+	lc := gtx
+	lc.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	dims := widget.Label{MaxLines: 1}.Layout(lc, c.th.Shaper, *c.Font, size, s, colorCall)
+	call := m.Stop()
+	y := r.Bounds.Max.Y - r.Baseline - (dims.Size.Y - dims.Baseline)
+	defer op.Offset(image.Pt(r.Bounds.Min.X, y)).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+// visibleLines returns the first and last line with a visible start
+func (c *CodeEditDef) visibleLines() (int, int) {
+	first, last := -1, -1
+	for i, s := range c.lineStarts {
+		c.regions = c.ed.Regions(s, s, c.regions)
+		if len(c.regions) > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		} else if first >= 0 {
+			break
+		}
+	}
+	if first < 0 {
+		// The view is inside a long wrapped line
+		pos, _ := c.ed.Selection()
+		first = c.lineOf(Min(pos, len(c.runes)))
+		last = first
+	}
+	// A wrapped line starting above the view can still be partly visible
+	return Max(0, first-1), last
+}
+
+// layoutBar draws the find/replace bar
+func (c *CodeEditDef) layoutBar(gtx C) D {
+	gtx.Constraints.Min.Y = 0
+	edit := func(e *EditDef) layout.FlexChild {
+		return layout.Flexed(1, func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return e.Layout(gtx)
+		})
+	}
+	rigid := func(w layout.Widget) layout.FlexChild { return layout.Rigid(w) }
+	row := layout.Flex{Alignment: layout.Middle}
+	dims := row.Layout(gtx, edit(&c.findEdit), rigid(c.buttons[0]), rigid(c.buttons[1]), rigid(c.buttons[4]))
+	if !c.replaceOpen {
+		return dims
+	}
+	o := op.Offset(image.Pt(0, dims.Size.Y)).Push(gtx.Ops)
+	d2 := row.Layout(gtx, edit(&c.replaceEdit), rigid(c.buttons[2]), rigid(c.buttons[3]))
+	o.Pop()
+	dims.Size.Y += d2.Size.Y
+	return dims
+}
+
+// Layout handles the keys and draws the editor
+func (c *CodeEditDef) Layout(gtx C) D {
+	mt, mb, ml, mr := ScaleInset(gtx, c.margin)
+	pt, pb, pl, pr := ScaleInset(gtx, c.padding)
+	c.handleKeys(gtx)
+	for {
+		if _, ok := c.ed.Update(gtx); !ok {
+			break
+		}
+	}
+	c.sync()
+	textSize := c.th.TextSize * unit.Sp(c.FontScale)
+	fg := c.Fg()
+	if !gtx.Enabled() {
+		fg = Disabled(fg)
+	}
+
+	// Find the size of the characters, which are all equal in a monospace font
+	m := op.Record(gtx.Ops)
+	lc := gtx
+	lc.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	cdims := widget.Label{MaxLines: 1}.Layout(lc, c.th.Shaper, *c.Font, textSize, "0000000000", op.CallOp{})
+	m.Stop()
+	charW, lineH := cdims.Size.X/10, cdims.Size.Y
+	width := Max(100, gtx.Constraints.Max.X-ml-mr)
+	if w := Px(gtx, c.width); w > 0 && w < width {
+		width = w
+	}
+	height := gtx.Constraints.Max.Y - mt - mb
+	if c.lines > 0 || height >= inf {
+		height = Max(c.lines, 1)*lineH + pt + pb
+		if c.lines == 0 {
+			height = 20*lineH + pt + pb
+		}
+	}
+	defer op.Offset(image.Pt(ml, mt)).Push(gtx.Ops).Pop()
+	box := image.Rect(0, 0, width, height)
+	rr := Px(gtx, c.th.BorderCornerRadius)
+	paint.FillShape(gtx.Ops, c.th.Bg[SurfaceContainerLowest], clip.UniformRRect(box, rr).Op(gtx.Ops))
+
+	// The find/replace bar is above the code
+	barH := 0
+	if c.findOpen {
+		o := op.Offset(image.Pt(pl, pt)).Push(gtx.Ops)
+		bc := gtx
+		bc.Constraints = layout.Constraints{Max: image.Pt(width-pl-pr, height)}
+		barH = c.layoutBar(bc).Size.Y + pt
+		o.Pop()
+	}
+
+	// The gutter with line numbers
+	digits := len(fmt.Sprint(len(c.lineStarts)))
+	gutterW := (digits+1)*charW + pl
+	codeRect := image.Rect(gutterW+pl, barH+pt, width-pr, height-pb)
+	cl := clip.UniformRRect(image.Rect(0, barH, width, height), rr).Push(gtx.Ops)
+	paint.FillShape(gtx.Ops, c.th.Bg[SurfaceContainer], clip.Rect{Min: image.Pt(0, barH), Max: image.Pt(gutterW, height)}.Op())
+	cl.Pop()
+
+	// Lay out the editor in a macro, so the highlights can be drawn below the text
+	defer op.Offset(codeRect.Min).Push(gtx.Ops).Pop()
+	ec := gtx
+	ec.Constraints = layout.Exact(codeRect.Size())
+	m = op.Record(gtx.Ops)
+	paint.ColorOp{}.Add(gtx.Ops)
+	transparent := m.Stop()
+	m = op.Record(gtx.Ops)
+	c.ed.Layout(ec, c.th.Shaper, *c.Font, textSize, transparent, c.selectionColor(gtx))
+	editorCall := m.Stop()
+	focused := gtx.Focused(&c.ed)
+	caret, _ := c.ed.Selection()
+	caretLine := c.lineOf(Min(caret, len(c.runes)))
+	cw := codeRect.Dx()
+	codeClip := clip.Rect{Min: image.Pt(-pl, 0), Max: image.Pt(cw, codeRect.Dy())}.Push(gtx.Ops)
+	// Current line, search matches and matching brackets
+	c.fillRegions(gtx, c.lineStarts[caretLine], c.lineEnd(caretLine), MulAlpha(c.th.Bg[Primary], 20), true, cw)
+	if c.findOpen {
+		n := utf8.RuneCountInString(c.find)
+		for _, p := range c.findMatches() {
+			c.fillRegions(gtx, p, p+n, MulAlpha(c.th.Bg[Tertiary], 70), false, 0)
+		}
+	}
+	if a, b := c.matchBracket(); a >= 0 {
+		col := MulAlpha(c.th.Bg[Primary], 70)
+		if b < 0 {
+			col = MulAlpha(c.th.Bg[Error], 90)
+		} else {
+			c.fillRegions(gtx, b, b+1, col, false, 0)
+		}
+		c.fillRegions(gtx, a, a+1, col, false, 0)
+	}
+	editorCall.Add(gtx.Ops)
+
+	// Draw the text of the visible lines, one segment at a time
+	first, last := c.visibleLines()
+	start, end := c.lineStarts[first], c.lineEnd(last)
+	i := sort.Search(len(c.segments), func(k int) bool { return c.segments[k].end > start })
+	for ; i < len(c.segments) && c.segments[i].start < end; i++ {
+		seg := c.segments[i]
+		col := c.tokenColor(seg.kind)
+		if !gtx.Enabled() {
+			col = Disabled(col)
+		}
+		// Segments are split at line breaks, and at the line wraps given by the regions
+		for _, piece := range strings.Split(string(c.runes[seg.start:seg.end]), "\n") {
+			runes := []rune(piece)
+			c.regions = c.ed.Regions(seg.start, seg.start+len(runes), c.regions)
+			for k, r := range c.regions {
+				n := len(runes)
+				if k < len(c.regions)-1 && charW > 0 {
+					n = Min(n, (r.Bounds.Dx()+charW/2)/charW)
+				}
+				if n > 0 && strings.TrimSpace(string(runes[:n])) != "" {
+					c.drawText(gtx, string(runes[:n]), r, col, textSize)
+				}
+				runes = runes[n:]
+			}
+			seg.start += len([]rune(piece)) + 1
+		}
+	}
+	// The caret is drawn here, because the editor text is transparent
+	if focused && gtx.Enabled() {
+		c.regions = c.ed.Regions(caret, caret, c.regions)
+		if len(c.regions) > 0 {
+			r := c.regions[0].Bounds
+			w := Max(1, Px(gtx, unit.Dp(1)))
+			paint.FillShape(gtx.Ops, fg, clip.Rect{Min: image.Pt(r.Min.X-w/2, r.Min.Y), Max: image.Pt(r.Min.X-w/2+w, r.Max.Y)}.Op())
+		}
+	}
+	codeClip.Pop()
+	// Line numbers
+	defer clip.Rect{Min: image.Pt(-codeRect.Min.X, 0), Max: image.Pt(0, codeRect.Dy())}.Push(gtx.Ops).Pop()
+	for n := first; n <= last; n++ {
+		c.regions = c.ed.Regions(c.lineStarts[n], c.lineStarts[n], c.regions)
+		if len(c.regions) == 0 {
+			continue
+		}
+		col := MulAlpha(fg, 120)
+		if n == caretLine {
+			col = fg
+		}
+		s := fmt.Sprint(n + 1)
+		r := c.regions[0]
+		r.Bounds.Min.X = -pl - (len(s)+1)*charW + charW/2
+		c.drawText(gtx, s, r, col, textSize)
+	}
+
+	// Border, emphasized when focused
+	bw := float32(Px(gtx, c.th.BorderThickness))
+	if focused {
+		paintBorder(gtx, box, c.th.Bg[Primary], bw*2, rr)
+	} else {
+		paintBorder(gtx, box, c.th.Fg[Outline], bw, rr)
+	}
+	return D{Size: image.Pt(width+ml+mr, height+mt+mb)}
+}
+
+// selectionColor returns the material for the selection
+func (c *CodeEditDef) selectionColor(gtx C) op.CallOp {
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: c.th.SelectionColor}.Add(gtx.Ops)
+	return m.Stop()
+}
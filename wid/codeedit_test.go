@@ -0,0 +1,32 @@
+package wid
+
+import (
+	"testing"
+)
+
+func TestCodeEditTabs(t *testing.T) {
+	value := "func f() {\n\tfoo()\n    bar()\n\tx\t= 1\n}"
+	c := &CodeEditDef{tabWidth: 4}
+	c.setTabs(value)
+	text := expandTabs(value, c.tabWidth)
+	if s := c.withTabs(text); s != value {
+		t.Errorf("unchanged text written as %q", s)
+	}
+	// Edited and new lines are indented with tabs, the others are kept
+	text = "func f() {\n    foo(1)\n    bar()\n    x   = 1\n    baz()\n}"
+	want := "func f() {\n\tfoo(1)\n    bar()\n\tx\t= 1\n\tbaz()\n}"
+	if s := c.withTabs(text); s != want {
+		t.Errorf("edited text written as %q, want %q", s, want)
+	}
+	// Moved lines keep their indentation
+	text = "func f() {\n    bar()\n    foo()\n}"
+	want = "func f() {\n    bar()\n\tfoo()\n}"
+	if s := c.withTabs(text); s != want {
+		t.Errorf("moved lines written as %q, want %q", s, want)
+	}
+	// A value without tabs is written as it is edited
+	c.setTabs("a\n    b")
+	if s := c.withTabs("a\n    b\n    c"); s != "a\n    b\n    c" {
+		t.Errorf("no tabs written as %q", s)
+	}
+}
@@ -0,0 +1,422 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// maxRecentColors is the number of colors kept in the recent colors row
+const maxRecentColors = 12
+
+var (
+	// recentColors is shared by all color pickers
+	recentColors []color.NRGBA
+	// hueImage is the rainbow used for the hue slider
+	hueImage paint.ImageOp
+)
+
+// swatchTones is the tones shown for each of the theme colors
+var swatchTones = []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 100}
+
+// ColorPickerDef is a panel for selecting a color, with a saturation/lightness area,
+// hue and alpha sliders, hex input and color swatches.
+type ColorPickerDef struct {
+	Base
+	value      *color.NRGBA
+	last       color.NRGBA
+	hue        float64
+	sat        float64
+	light      float64
+	alpha      float64
+	hex        string
+	hexEdit    layout.Widget
+	showRecent bool
+	noAlpha    bool
+	area       paint.ImageOp
+	areaHue    float64
+	// The area, hue and alpha slider are dragged. Also used as tags for pointer events.
+	areaDrag  bool
+	hueDrag   bool
+	alphaDrag bool
+	swatches  []gesture.Click
+	recent    [maxRecentColors]gesture.Click
+}
+
+// ColorOption is options specific to color pickers
+type ColorOption func(p *ColorPickerDef)
+
+func (o ColorOption) apply(cfg interface{}) {
+	if p, ok := cfg.(*ColorPickerDef); ok {
+		o(p)
+	}
+}
+
+// RecentColors is an option that shows a row with the colors selected last.
+// The recent colors are shared by all color pickers.
+func RecentColors() ColorOption {
+	return func(p *ColorPickerDef) {
+		p.showRecent = true
+	}
+}
+
+// NoAlpha is an option that hides the alpha slider. Colors will always be opaque.
+func NoAlpha() ColorOption {
+	return func(p *ColorPickerDef) {
+		p.noAlpha = true
+	}
+}
+
+// ColorPicker returns a widget for selecting a color. The color is updated while dragging.
+func ColorPicker(th *Theme, value *color.NRGBA, options ...Option) layout.Widget {
+	p := &ColorPickerDef{value: value, areaHue: -1}
+	p.th = th
+	p.Font = &th.DefaultFont
+	p.FontScale = 1.0
+	p.padding = th.DefaultPadding
+	p.width = unit.Dp(360)
+	for _, option := range options {
+		option.apply(p)
+	}
+	p.hexEdit = Edit(th, &p.hex, Hint("#rrggbb"), Untracked(), Live(), Debounce(300*time.Millisecond), Do(p.hexChanged))
+	p.sync()
+	return p.Layout
+}
+
+// HexColor returns the color as a text like "#12abef", with alpha added if not opaque.
+func HexColor(c color.NRGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// ParseHexColor converts a text like "#12abef" or "12abef80" to a color.
+func ParseHexColor(s string) (color.NRGBA, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 && len(s) != 8 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	if len(s) == 6 {
+		return RGB(uint32(v)), true
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
+// sync reads the bound color, and updates hue, saturation and lightness if it was changed elsewhere
+func (p *ColorPickerDef) sync() {
+	GuiLock.RLock()
+	c := *p.value
+	GuiLock.RUnlock()
+	if c == p.last && p.hex != "" {
+		return
+	}
+	p.last = c
+	h, s, l := Rgb2hsl(c)
+	// Keep the hue for grays, where it is undefined
+	if s > 0 && l > 0 && l < 1 {
+		p.hue = h
+	}
+	p.sat, p.light, p.alpha = s, l, float64(c.A)/255
+	p.hex = HexColor(c)
+}
+
+// setColor writes a new color to the bound variable
+func (p *ColorPickerDef) setColor(c color.NRGBA) {
+	if p.noAlpha {
+		c.A = 255
+	}
+	GuiLock.Lock()
+	changed := *p.value != c
+	old := *p.value
+	*p.value = c
+	p.record(p.value, old)
+	GuiLock.Unlock()
+	p.last = c
+	p.hex = HexColor(c)
+	if changed && p.onUserChange != nil {
+		p.onUserChange()
+	}
+}
+
+// update writes the color given by hue, saturation, lightness and alpha
+func (p *ColorPickerDef) update() {
+	c := Hsl2rgb(p.hue, p.sat, p.light)
+	c.A = uint8(p.alpha*255 + 0.5)
+	p.setColor(c)
+}
+
+// pick selects a complete color, like from a swatch, and adds it to the recent colors.
+func (p *ColorPickerDef) pick(c color.NRGBA) {
+	p.setColor(c)
+	p.last = color.NRGBA{}
+	p.sync()
+	addRecent(c)
+}
+
+// hexChanged is called when a new hex value is typed
+func (p *ColorPickerDef) hexChanged() {
+	if c, ok := ParseHexColor(p.hex); ok {
+		p.pick(c)
+	}
+}
+
+// addRecent puts the color first in the recent colors
+func addRecent(c color.NRGBA) {
+	for i, r := range recentColors {
+		if r == c {
+			recentColors = append(recentColors[:i], recentColors[i+1:]...)
+			break
+		}
+	}
+	recentColors = append([]color.NRGBA{c}, recentColors...)
+	if len(recentColors) > maxRecentColors {
+		recentColors = recentColors[:maxRecentColors]
+	}
+}
+
+// handleDrag processes pointer events for one of the areas, and calls f with the
+// position as fractions of the size. It returns true when the pointer is released.
+func handleDrag(gtx C, dragging *bool, size image.Point, f func(x, y float64)) (released bool) {
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: dragging, Kinds: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel})
+		if !ok {
+			break
+		}
+		e, ok := ev.(pointer.Event)
+		if !ok || size.X == 0 || size.Y == 0 {
+			continue
+		}
+		if e.Kind == pointer.Cancel {
+			*dragging = false
+			continue
+		}
+		x := float64(Clamp(e.Position.X/float32(size.X), 0, 1))
+		y := float64(Clamp(e.Position.Y/float32(size.Y), 0, 1))
+		f(x, y)
+		*dragging = e.Kind != pointer.Release
+		released = released || e.Kind == pointer.Release
+	}
+	return released
+}
+
+func (p *ColorPickerDef) Layout(gtx C) D {
+	p.sync()
+	pt, pb, pl, pr := ScaleInset(gtx, p.padding)
+	width := Min(gtx.Constraints.Max.X-pl-pr, Px(gtx, p.width))
+	barH := gtx.Sp(p.th.TextSize * unit.Sp(p.FontScale) * 1.2)
+	gap := barH / 2
+	areaSize := image.Pt(width, width*3/5)
+	barSize := image.Pt(width, barH)
+	fg := p.th.Fg[Canvas]
+
+	// Handle input before drawing, using the sizes from this frame
+	if handleDrag(gtx, &p.areaDrag, areaSize, func(x, y float64) {
+		p.sat, p.light = x, 1-y
+		p.update()
+	}) {
+		addRecent(p.last)
+	}
+	if handleDrag(gtx, &p.hueDrag, barSize, func(x, y float64) {
+		p.hue = Min(x*360, 359.9)
+		p.update()
+	}) {
+		addRecent(p.last)
+	}
+	if handleDrag(gtx, &p.alphaDrag, barSize, func(x, y float64) {
+		p.alpha = x
+		p.update()
+	}) {
+		addRecent(p.last)
+	}
+	tones := []color.NRGBA{p.th.PrimaryColor, p.th.SecondaryColor, p.th.TertiaryColor, p.th.NeutralColor, p.th.ErrorColor}
+	for len(p.swatches) < len(tones)*len(swatchTones) {
+		p.swatches = append(p.swatches, gesture.Click{})
+	}
+	for i := range p.swatches {
+		for {
+			ev, ok := p.swatches[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind == gesture.KindClick {
+				p.pick(Tone(tones[i/len(swatchTones)], swatchTones[i%len(swatchTones)]))
+			}
+		}
+	}
+	for i := range p.recent {
+		for {
+			ev, ok := p.recent[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind == gesture.KindClick && i < len(recentColors) {
+				p.pick(recentColors[i])
+			}
+		}
+	}
+
+	defer op.Offset(image.Pt(pl, pt)).Push(gtx.Ops).Pop()
+	y := 0
+	// The saturation/lightness area for the current hue
+	p.layoutArea(gtx, areaSize)
+	thumb(gtx, image.Pt(int(p.sat*float64(areaSize.X)), int((1-p.light)*float64(areaSize.Y))), barH/3)
+	y += areaSize.Y + gap
+
+	// Hue slider
+	o := op.Offset(image.Pt(0, y)).Push(gtx.Ops)
+	layoutImage(gtx, hueImage, barSize)
+	thumb(gtx, image.Pt(int(p.hue/360*float64(width)), barH/2), barH/3)
+	dragArea(gtx, &p.hueDrag, barSize)
+	o.Pop()
+	y += barH + gap
+
+	// Alpha slider, with a gradient on a checkered background
+	if !p.noAlpha {
+		o = op.Offset(image.Pt(0, y)).Push(gtx.Ops)
+		paintChecker(gtx, barSize, barH/3)
+		c := p.last
+		c.A = 255
+		cl := clip.Rect{Max: barSize}.Push(gtx.Ops)
+		paint.LinearGradientOp{Stop1: f32.Pt(0, 0), Color1: WithAlpha(c, 0), Stop2: f32.Pt(float32(width), 0), Color2: c}.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		cl.Pop()
+		thumb(gtx, image.Pt(int(p.alpha*float64(width)), barH/2), barH/3)
+		dragArea(gtx, &p.alphaDrag, barSize)
+		o.Pop()
+		y += barH + gap
+	}
+
+	// Preview of the color, and the hex value
+	o = op.Offset(image.Pt(0, y)).Push(gtx.Ops)
+	c := gtx
+	c.Constraints.Min.X = width - barH*3
+	c.Constraints.Max.X = c.Constraints.Min.X
+	c.Constraints.Min.Y = 0
+	eo := op.Offset(image.Pt(barH*3, 0)).Push(gtx.Ops)
+	dims := p.hexEdit(c)
+	eo.Pop()
+	preview := image.Rect(0, dims.Size.Y/6, barH*5/2, dims.Size.Y*5/6)
+	po := op.Offset(preview.Min).Push(gtx.Ops)
+	paintChecker(gtx, preview.Size(), barH/3)
+	paint.FillShape(gtx.Ops, p.last, clip.Rect{Max: preview.Size()}.Op())
+	paintBorder(gtx, image.Rectangle{Max: preview.Size()}, MulAlpha(fg, 128), 1, 0)
+	po.Pop()
+	o.Pop()
+	y += dims.Size.Y + gap/2
+
+	// Swatches with the tones of the theme colors
+	sw := width / len(swatchTones)
+	for i := range p.swatches {
+		col := Tone(tones[i/len(swatchTones)], swatchTones[i%len(swatchTones)])
+		pos := image.Pt((i%len(swatchTones))*sw, y+(i/len(swatchTones))*sw*2/3)
+		p.layoutSwatch(gtx, &p.swatches[i], col, pos, image.Pt(sw, sw*2/3))
+	}
+	y += len(tones) * sw * 2 / 3
+
+	// The recent colors
+	if p.showRecent && len(recentColors) > 0 {
+		y += gap / 2
+		for i, col := range recentColors {
+			p.layoutSwatch(gtx, &p.recent[i], col, image.Pt(i*sw, y), image.Pt(sw, sw*2/3))
+		}
+		y += sw * 2 / 3
+	}
+	return D{Size: image.Pt(width+pl+pr, y+pt+pb)}
+}
+
+// layoutArea draws the saturation/lightness area. The image is only made again when the hue changes.
+func (p *ColorPickerDef) layoutArea(gtx C, size image.Point) {
+	if p.areaHue != p.hue {
+		const n = 64
+		img := image.NewNRGBA(image.Rect(0, 0, n, n))
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				img.SetNRGBA(x, y, Hsl2rgb(p.hue, float64(x)/(n-1), 1-float64(y)/(n-1)))
+			}
+		}
+		p.area = paint.NewImageOp(img)
+		p.area.Filter = paint.FilterLinear
+		p.areaHue = p.hue
+	}
+	layoutImage(gtx, p.area, size)
+	dragArea(gtx, &p.areaDrag, size)
+}
+
+// layoutSwatch draws a clickable rectangle filled with the color
+func (p *ColorPickerDef) layoutSwatch(gtx C, click *gesture.Click, col color.NRGBA, pos image.Point, size image.Point) {
+	defer op.Offset(pos).Push(gtx.Ops).Pop()
+	r := image.Rectangle{Max: size}
+	paint.FillShape(gtx.Ops, col, clip.Rect(r).Op())
+	if click.Hovered() || col == p.last {
+		paintBorder(gtx, r.Inset(1), p.th.Fg[Outline], float32(Px(gtx, unit.Dp(2))), 0)
+	}
+	defer clip.Rect(r).Push(gtx.Ops).Pop()
+	click.Add(gtx.Ops)
+	pointer.CursorPointer.Add(gtx.Ops)
+}
+
+// layoutImage draws the image scaled to the given size
+func layoutImage(gtx C, img paint.ImageOp, size image.Point) {
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	sz := img.Size()
+	scale := f32.Pt(float32(size.X)/float32(sz.X), float32(size.Y)/float32(sz.Y))
+	defer op.Affine(f32.Affine2D{}.Scale(f32.Point{}, scale)).Push(gtx.Ops).Pop()
+	img.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+}
+
+// dragArea sets up the area used for pointer events
+func dragArea(gtx C, tag event.Tag, size image.Point) {
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	event.Op(gtx.Ops, tag)
+	pointer.CursorCrosshair.Add(gtx.Ops)
+}
+
+// thumb draws a circle with black and white outline at the position
+func thumb(gtx C, pos image.Point, r int) {
+	rect := image.Rect(pos.X-r, pos.Y-r, pos.X+r, pos.Y+r)
+	w := float32(Px(gtx, unit.Dp(1.5)))
+	paint.FillShape(gtx.Ops, Black, clip.Stroke{Path: clip.Ellipse(rect).Path(gtx.Ops), Width: w * 3}.Op())
+	paint.FillShape(gtx.Ops, White, clip.Stroke{Path: clip.Ellipse(rect).Path(gtx.Ops), Width: w}.Op())
+}
+
+// paintChecker fills the area with a checkered pattern, used behind transparent colors
+func paintChecker(gtx C, size image.Point, sq int) {
+	sq = Max(sq, 1)
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	paint.Fill(gtx.Ops, RGB(0xffffff))
+	for y := 0; y*sq < size.Y; y++ {
+		for x := y % 2; x*sq < size.X; x += 2 {
+			paint.FillShape(gtx.Ops, RGB(0xcccccc), clip.Rect{Min: image.Pt(x*sq, y*sq), Max: image.Pt(x*sq+sq, y*sq+sq)}.Op())
+		}
+	}
+}
+
+func init() {
+	img := image.NewNRGBA(image.Rect(0, 0, 360, 1))
+	for x := 0; x < 360; x++ {
+		img.SetNRGBA(x, 0, Hsl2rgb(float64(x), 1, 0.5))
+	}
+	hueImage = paint.NewImageOp(img)
+	hueImage.Filter = paint.FilterLinear
+}
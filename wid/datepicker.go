@@ -0,0 +1,548 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"gioui.org/gesture"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// rangeSep is the text between the two dates of a date range
+const rangeSep = " - "
+
+var (
+	calendarIcon *Icon
+	prevIcon     *Icon
+	nextIcon     *Icon
+)
+
+// DatePickerDef is a text field for a date, with a calendar popup.
+// It embeds an EditDef, so all the options for edits can be used.
+type DatePickerDef struct {
+	EditDef
+	date       *time.Time
+	end        *time.Time
+	isRange    bool
+	dateLayout string
+	min        time.Time
+	max        time.Time
+	disabled   func(t time.Time) bool
+	weekStart  time.Weekday
+	open       bool
+	hasFocus   bool
+	cal        calendar
+}
+
+// calendar is the state of the calendar popup
+type calendar struct {
+	// month is the first day of the month shown
+	month time.Time
+	// first and last is the selection in the calendar, last is only used for ranges
+	first time.Time
+	last  time.Time
+	days  [42]gesture.Click
+	nav   [4]gesture.Click
+}
+
+// DateOption is options specific to date pickers
+type DateOption func(d *DatePickerDef)
+
+func (o DateOption) apply(cfg interface{}) {
+	if d, ok := cfg.(*DatePickerDef); ok {
+		o(d)
+	}
+}
+
+// DateLayout is an option setting the layout used for showing and parsing dates.
+// See time.Layout for the syntax. The default is "2006-01-02".
+func DateLayout(layout string) DateOption {
+	return func(d *DatePickerDef) {
+		d.dateLayout = layout
+	}
+}
+
+// MinDate is an option setting the first date that can be selected
+func MinDate(t time.Time) DateOption {
+	return func(d *DatePickerDef) {
+		d.min = dayOf(t)
+	}
+}
+
+// MaxDate is an option setting the last date that can be selected
+func MaxDate(t time.Time) DateOption {
+	return func(d *DatePickerDef) {
+		d.max = dayOf(t)
+	}
+}
+
+// DisabledDays is an option giving a function that returns true for dates that can not be selected
+func DisabledDays(f func(t time.Time) bool) DateOption {
+	return func(d *DatePickerDef) {
+		d.disabled = f
+	}
+}
+
+// WeekStart is an option setting the first day of the week in the calendar. Default is Monday.
+func WeekStart(day time.Weekday) DateOption {
+	return func(d *DatePickerDef) {
+		d.weekStart = day
+	}
+}
+
+// Weekend returns true for saturdays and sundays. It can be used with DisabledDays()
+func Weekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// DatePicker returns a text field for the date, with a button opening a calendar.
+// Only the date part of the variable is changed. The options can be both
+// edit options and date options.
+func DatePicker(th *Theme, date *time.Time, options ...any) layout.Widget {
+	d := newDatePicker(th, options)
+	d.date = date
+	return d.Layout
+}
+
+// DateRangePicker is like DatePicker, but selects the two dates from and to.
+// In the calendar, the first click selects the start date, and the second the end date.
+func DateRangePicker(th *Theme, from *time.Time, to *time.Time, options ...any) layout.Widget {
+	d := newDatePicker(th, options)
+	d.date = from
+	d.end = to
+	d.isRange = true
+	return d.Layout
+}
+
+func newDatePicker(th *Theme, options []any) *DatePickerDef {
+	d := &DatePickerDef{EditDef: DefaultEditDef(th), dateLayout: "2006-01-02", weekStart: time.Monday}
+	d.style = OutlinedField
+	i := 0
+	d.DpNo = &i
+	for _, option := range options {
+		if v, ok := option.(DateOption); ok {
+			v(d)
+		} else if v, ok := option.(UIRole); ok {
+			d.role = v
+		} else if v, ok := option.(FieldStyle); ok {
+			d.style = v
+		} else if v, ok := option.(Option); ok {
+			v.apply(&d.EditDef)
+		}
+	}
+	if d.hint == "" {
+		d.hint = layoutHint(d.dateLayout)
+		if d.isRange {
+			d.hint += rangeSep + d.hint
+		}
+	}
+	d.trailIcon.icon = calendarIcon
+	d.trailIcon.onClick = func() {
+		if !d.open {
+			d.openCalendar(time.Now())
+		} else {
+			d.open = false
+		}
+		d.focusEditor = true
+	}
+	// Enter will update the variables and close the calendar
+	onSubmit := d.onSubmit
+	d.onSubmit = func(text string) {
+		d.commit()
+		d.open = false
+		if onSubmit != nil {
+			onSubmit(d.Text())
+		}
+	}
+	d.Submit = true
+	return d
+}
+
+// layoutHint converts a time layout to a text like "yyyy-mm-dd"
+func layoutHint(layout string) string {
+	return strings.NewReplacer("2006", "yyyy", "January", "month", "Jan", "mmm", "01", "mm", "02", "dd",
+		"06", "yy", "1", "m", "2", "d").Replace(layout)
+}
+
+// dayOf returns midnight at the start of the day
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// sameDay checks if a and b is the same date. Two zero times are also equal.
+func sameDay(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() && b.IsZero()
+	}
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// withDate returns the date from t, combined with the time of day from old
+func withDate(old time.Time, t time.Time) time.Time {
+	if old.IsZero() {
+		return dayOf(t)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), old.Hour(), old.Minute(), old.Second(), old.Nanosecond(), old.Location())
+}
+
+// allowed checks if the date is inside min/max and not disabled
+func (d *DatePickerDef) allowed(t time.Time) bool {
+	t = dayOf(t)
+	if !d.min.IsZero() && t.Before(d.min) || !d.max.IsZero() && t.After(d.max) {
+		return false
+	}
+	return d.disabled == nil || !d.disabled(t)
+}
+
+// dates returns the bound dates
+func (d *DatePickerDef) dates() (first, last time.Time) {
+	GuiLock.RLock()
+	defer GuiLock.RUnlock()
+	if d.date != nil {
+		first = *d.date
+	}
+	if d.end != nil {
+		last = *d.end
+	}
+	return first, last
+}
+
+// format returns the bound dates as text
+func (d *DatePickerDef) format() string {
+	first, last := d.dates()
+	s := ""
+	if !first.IsZero() {
+		s = first.Format(d.dateLayout)
+	}
+	if d.isRange && (!first.IsZero() || !last.IsZero()) {
+		s += rangeSep
+		if !last.IsZero() {
+			s += last.Format(d.dateLayout)
+		}
+	}
+	return s
+}
+
+// parse converts a text to a date. An empty text gives a zero date.
+func (d *DatePickerDef) parse(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, true
+	}
+	t, err := time.ParseInLocation(d.dateLayout, s, time.Local)
+	return t, err == nil && d.allowed(t)
+}
+
+// parseText converts the edited text to dates
+func (d *DatePickerDef) parseText() (first, last time.Time, ok bool) {
+	if !d.isRange {
+		first, ok = d.parse(d.Text())
+		return first, time.Time{}, ok
+	}
+	a, b, found := strings.Cut(d.Text(), rangeSep)
+	first, ok1 := d.parse(a)
+	last, ok2 := d.parse(b)
+	if !ok1 || !ok2 || !found && strings.TrimSpace(a) != "" {
+		return first, last, false
+	}
+	if !first.IsZero() && !last.IsZero() && last.Before(first) {
+		first, last = last, first
+	}
+	return first, last, true
+}
+
+// setDates updates the bound variables, and calls the Do() handler if they changed.
+func (d *DatePickerDef) setDates(first, last time.Time) {
+	changed := false
+	GuiLock.Lock()
+	if d.date != nil && !sameDay(*d.date, first) {
+		old := *d.date
+		if first.IsZero() {
+			*d.date = first
+		} else {
+			*d.date = withDate(*d.date, first)
+		}
+		d.record(d.date, old)
+		changed = true
+	}
+	if d.end != nil && !sameDay(*d.end, last) {
+		old := *d.end
+		if last.IsZero() {
+			*d.end = last
+		} else {
+			*d.end = withDate(*d.end, last)
+		}
+		d.record(d.end, old)
+		changed = true
+	}
+	GuiLock.Unlock()
+	d.SetText(d.format())
+	n := d.Len()
+	d.SetCaret(n, n)
+	if changed && d.onUserChange != nil {
+		d.onUserChange()
+	}
+}
+
+// commit updates the variables from the edited text. Invalid text is replaced by the old value.
+func (d *DatePickerDef) commit() {
+	if first, last, ok := d.parseText(); ok {
+		d.setDates(first, last)
+	} else {
+		d.SetText(d.format())
+	}
+}
+
+// openCalendar shows the calendar popup, with the month of the current value
+func (d *DatePickerDef) openCalendar(now time.Time) {
+	d.cal.first, d.cal.last = d.dates()
+	if t, _, ok := d.parseText(); ok && !t.IsZero() {
+		now = t
+	} else if !d.cal.first.IsZero() {
+		now = d.cal.first
+	}
+	d.cal.month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	d.open = true
+}
+
+// dayAt returns the date shown in cell i of the calendar
+func (d *DatePickerDef) dayAt(i int) time.Time {
+	m := d.cal.month
+	ofs := (int(m.Weekday()) - int(d.weekStart) + 7) % 7
+	return time.Date(m.Year(), m.Month(), 1-ofs+i, 0, 0, 0, 0, time.Local)
+}
+
+// pick is called when a day is clicked in the calendar
+func (d *DatePickerDef) pick(t time.Time) {
+	if !d.isRange {
+		d.setDates(t, time.Time{})
+		d.open = false
+		return
+	}
+	if d.cal.first.IsZero() || !d.cal.last.IsZero() {
+		// Start a new range
+		d.cal.first, d.cal.last = t, time.Time{}
+		d.SetText(t.Format(d.dateLayout) + rangeSep)
+		n := d.Len()
+		d.SetCaret(n, n)
+		return
+	}
+	if t.Before(d.cal.first) {
+		d.cal.first, d.cal.last = t, d.cal.first
+	} else {
+		d.cal.last = t
+	}
+	d.setDates(d.cal.first, d.cal.last)
+	d.open = false
+}
+
+// handleCalendar processes clicks in the calendar, and keys while it is open
+func (d *DatePickerDef) handleCalendar(gtx C) {
+	for i := range d.cal.nav {
+		for {
+			ev, ok := d.cal.nav[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind == gesture.KindClick {
+				// Previous/next month and previous/next year
+				d.cal.month = d.cal.month.AddDate([]int{0, 0, -1, 1}[i], []int{-1, 1, 0, 0}[i], 0)
+			}
+		}
+	}
+	for i := range d.cal.days {
+		for {
+			ev, ok := d.cal.days[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind == gesture.KindClick && d.open {
+				if t := d.dayAt(i); d.allowed(t) {
+					d.pick(t)
+				}
+			}
+		}
+	}
+	if !d.open {
+		return
+	}
+	for {
+		ev, ok := gtx.Event(
+			key.Filter{Focus: &d.Editor, Name: key.NameEscape},
+			key.Filter{Focus: &d.Editor, Name: key.NamePageUp, Optional: key.ModShift},
+			key.Filter{Focus: &d.Editor, Name: key.NamePageDown, Optional: key.ModShift})
+		if !ok {
+			break
+		}
+		ke, ok := ev.(key.Event)
+		if !ok || ke.State != key.Press {
+			continue
+		}
+		if ke.Name == key.NameEscape {
+			d.open = false
+			continue
+		}
+		// PageUp/PageDown changes month, and with shift, the year
+		n := 1
+		if ke.Name == key.NamePageUp {
+			n = -1
+		}
+		if ke.Modifiers.Contain(key.ModShift) {
+			d.cal.month = d.cal.month.AddDate(n, 0, 0)
+		} else {
+			d.cal.month = d.cal.month.AddDate(0, n, 0)
+		}
+	}
+}
+
+func (d *DatePickerDef) Layout(gtx C) D {
+	focused := gtx.Focused(&d.Editor)
+	if !focused {
+		if d.hasFocus {
+			d.commit()
+		}
+		d.open = false
+		if s := d.format(); s != d.Text() {
+			d.SetText(s)
+		}
+	} else if d.style == PlainField && !d.hasFocus {
+		// Plain fields have no calendar button, so the calendar opens when focused
+		d.openCalendar(gtx.Now)
+	}
+	d.hasFocus = focused
+	d.handleCalendar(gtx)
+	d.setError("")
+	if focused {
+		t, _, ok := d.parseText()
+		if !ok {
+			d.setError("Invalid date")
+		} else if d.open && !t.IsZero() {
+			// Show the month of a valid typed date
+			d.cal.month = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+		}
+	}
+	dims := d.EditDef.Layout(gtx)
+	if d.open {
+		mt, mb, ml, _ := ScaleInset(gtx, d.margin)
+		layoutPopup(gtx, d.th, &d.cal, image.Pt(ml, dims.Size.Y-mb-mt/2), d.layoutCalendar)
+	}
+	return dims
+}
+
+// layoutCalendar draws the month grid with navigation buttons
+func (d *DatePickerDef) layoutCalendar(gtx C) D {
+	th := d.th
+	textSize := th.TextSize * unit.Sp(d.FontScale)
+	fg := th.Fg[SurfaceContainerHigh]
+	cell := gtx.Sp(textSize) * 5 / 2
+	pad := cell / 4
+	// Header with month and year navigation
+	y := pad
+	navs := []struct {
+		x    int
+		icon *Icon
+	}{{pad, prevIcon}, {pad + cell*5/2, nextIcon}, {pad + cell*7/2, prevIcon}, {pad + cell*6, nextIcon}}
+	for i, n := range navs {
+		o := op.Offset(image.Pt(n.x, y)).Push(gtx.Ops)
+		if d.cal.nav[i].Hovered() {
+			paint.FillShape(gtx.Ops, MulAlpha(fg, 24), clip.Ellipse{Max: image.Pt(cell, cell)}.Op(gtx.Ops))
+		}
+		c := gtx
+		c.Constraints = layout.Exact(image.Pt(cell, cell))
+		layout.UniformInset(unit.Dp(textSize/3)).Layout(c, func(gtx C) D {
+			return n.icon.Layout(gtx, fg)
+		})
+		cl := clip.Rect{Max: image.Pt(cell, cell)}.Push(gtx.Ops)
+		d.cal.nav[i].Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		cl.Pop()
+		o.Pop()
+	}
+	d.centerText(gtx, d.cal.month.Format("Jan"), image.Pt(pad+cell, y), cell*3/2, cell, fg)
+	d.centerText(gtx, d.cal.month.Format("2006"), image.Pt(pad+cell*9/2, y), cell*3/2, cell, fg)
+	y += cell
+	// Week day names
+	for i := 0; i < 7; i++ {
+		name := time.Weekday((int(d.weekStart) + i) % 7).String()[:2]
+		d.centerText(gtx, name, image.Pt(pad+i*cell, y), cell, cell*3/4, MulAlpha(fg, 180))
+	}
+	y += cell * 3 / 4
+	// The days of the month
+	for i := range d.cal.days {
+		t := d.dayAt(i)
+		if t.Month() != d.cal.month.Month() {
+			continue
+		}
+		pos := image.Pt(pad+(i%7)*cell, y+(i/7)*cell)
+		o := op.Offset(pos).Push(gtx.Ops)
+		d.layoutDay(gtx, i, t, cell)
+		o.Pop()
+	}
+	return D{Size: image.Pt(7*cell+2*pad, y+6*cell+pad)}
+}
+
+// layoutDay draws one day in the calendar
+func (d *DatePickerDef) layoutDay(gtx C, i int, t time.Time, cell int) {
+	th := d.th
+	fg := th.Fg[SurfaceContainerHigh]
+	first, last := d.cal.first, d.cal.last
+	circle := clip.Ellipse{Max: image.Pt(cell, cell)}
+	selected := sameDay(t, first) || sameDay(t, last)
+	allowed := d.allowed(t)
+	// Days inside a range are shown with a band between the start and end dates
+	if d.isRange && !first.IsZero() && !last.IsZero() && !t.Before(dayOf(first)) && !t.After(dayOf(last)) {
+		band := image.Rect(0, cell/10, cell, cell-cell/10)
+		if sameDay(t, first) {
+			band.Min.X = cell / 2
+		}
+		if sameDay(t, last) {
+			band.Max.X = cell / 2
+		}
+		paint.FillShape(gtx.Ops, th.Bg[PrimaryContainer], clip.Rect(band).Op())
+		fg = th.Fg[PrimaryContainer]
+	}
+	if selected {
+		paint.FillShape(gtx.Ops, th.Bg[Primary], circle.Op(gtx.Ops))
+		fg = th.Fg[Primary]
+	} else if sameDay(t, time.Now()) {
+		fg = th.Bg[Primary]
+		w := float32(Px(gtx, unit.Dp(1)))
+		paint.FillShape(gtx.Ops, fg, clip.Stroke{Path: circle.Path(gtx.Ops), Width: w}.Op())
+	}
+	if !allowed {
+		fg = Disabled(fg)
+	} else if d.cal.days[i].Hovered() && !selected {
+		paint.FillShape(gtx.Ops, MulAlpha(fg, 24), circle.Op(gtx.Ops))
+	}
+	d.centerText(gtx, t.Format("2"), image.Point{}, cell, cell, fg)
+	if allowed {
+		defer clip.Rect{Max: image.Pt(cell, cell)}.Push(gtx.Ops).Pop()
+		d.cal.days[i].Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+	}
+}
+
+// centerText draws the text centered in the box at pos with size w,h
+func (d *DatePickerDef) centerText(gtx C, s string, pos image.Point, w, h int, col color.NRGBA) {
+	call, dims := d.recordText(gtx, s, d.th.TextSize*unit.Sp(d.FontScale), col)
+	defer op.Offset(pos.Add(image.Pt((w-dims.Size.X)/2, (h-dims.Size.Y)/2))).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+func init() {
+	calendarIcon, _ = NewIcon(icons.ActionEvent)
+	prevIcon, _ = NewIcon(icons.NavigationChevronLeft)
+	nextIcon, _ = NewIcon(icons.NavigationChevronRight)
+}
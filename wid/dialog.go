@@ -1,66 +1,292 @@
 package wid
 
 import (
+	"gioui.org/io/key"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/unit"
 	"image"
 	"math"
+	"slices"
+	"sync"
 	"time"
 )
 
+// Result is the outcome of a modal dialog, sent on the channel returned by ShowModal
+type Result struct {
+	// Button is the number given to the dialog button clicked, or Cancelled
+	Button int
+	// Value is the value entered in the dialog, when it asks for one
+	Value any
+}
+
+// Cancelled is the button number when a dialog is closed by Escape or Hide()
+const Cancelled = -1
+
+// modal is a dialog in the stack of dialogs shown
+type modal struct {
+	w      layout.Widget
+	result chan Result
+	// start is the time the dialog was first drawn, and startX, startY is the
+	// mouse position then. The dialog grows from there.
+	start          time.Time
+	startX, startY int
+	// enter and escape are the functions of the default and cancel buttons,
+	// found while the dialog is drawn
+	enter, escape func()
+	// invalid is set by edits with invalid text, drawn before the default button
+	invalid bool
+	// submit is set when Enter is pressed in an edit
+	submit bool
+	// closed is called when the dialog is closed
+	closed func()
+}
+
 var (
-	dialog          layout.Widget
-	dialogStartTime time.Time
-	startX          int
-	startY          int
+	dialogMu sync.Mutex
+	dialogs  []*modal
+	// current is the dialog being drawn
+	current *modal
 )
 
+// Show shows the dialog on top of the form and the dialogs already shown
 func Show(d layout.Widget) {
-	dialog = d
-	dialogStartTime = time.Now()
-	startX = int(mouseX)
-	startY = int(mouseY)
+	ShowModal(d)
+}
+
+// ShowModal shows the dialog on top of the form and the dialogs already shown, and returns a channel
+// giving the result when the dialog is closed. It can be called from any goroutine, and a worker
+// goroutine can wait for the result. The gui goroutine must not wait, as it draws the dialog.
+func ShowModal(d layout.Widget) <-chan Result {
+	m := &modal{w: d, result: make(chan Result, 1)}
+	dialogMu.Lock()
+	dialogs = append(dialogs, m)
+	dialogMu.Unlock()
+	if invalidate != nil {
+		Invalidate()
+	}
+	return m.result
 }
 
+// Hide closes the dialog on top, with the result Cancelled
 func Hide() {
-	dialog = nil
-	dialogStartTime = time.Time{}
+	CloseModal(Result{Button: Cancelled})
+}
+
+// CloseModal closes the dialog on top, and sends the result to its channel
+func CloseModal(r Result) {
+	dialogMu.Lock()
+	var m *modal
+	if len(dialogs) > 0 {
+		m = dialogs[len(dialogs)-1]
+	}
+	dialogMu.Unlock()
+	closeDialog(m, r)
+}
+
+// closeDialog closes the dialog, also when it is not on top, and sends the result to its channel.
+// Nothing is done if the dialog is already closed.
+func closeDialog(m *modal, r Result) {
+	dialogMu.Lock()
+	i := slices.Index(dialogs, m)
+	if m == nil || i < 0 {
+		dialogMu.Unlock()
+		return
+	}
+	dialogs = slices.Delete(dialogs, i, i+1)
+	dialogMu.Unlock()
+	if m.closed != nil {
+		m.closed()
+	}
+	m.result <- r
+	close(m.result)
+	if invalidate != nil {
+		Invalidate()
+	}
 }
 
+// dialogShown is true when there is at least one dialog shown
+func dialogShown() bool {
+	dialogMu.Lock()
+	defer dialogMu.Unlock()
+	return len(dialogs) > 0
+}
+
+// layoutDialogs draws the stack of dialogs. Only the dialog on top is enabled,
+// so the focus and the clicks are kept inside it.
+func layoutDialogs(gtx C) {
+	dialogMu.Lock()
+	list := append([]*modal(nil), dialogs...)
+	dialogMu.Unlock()
+	for i, m := range list {
+		c := gtx
+		if i < len(list)-1 {
+			c = gtx.Disabled()
+		}
+		if m.start.IsZero() {
+			m.start, m.startX, m.startY = gtx.Now, mouseX, mouseY
+			// The focus is moved away from the form or dialog below
+			gtx.Execute(key.FocusCmd{Tag: nil})
+		}
+		m.enter, m.escape, m.invalid, m.submit = nil, nil, false, false
+		current = m
+		m.w(c)
+		current = nil
+		if m.submit && m.enter != nil {
+			m.enter()
+			gtx.Execute(op.InvalidateCmd{})
+		}
+	}
+}
+
+// handleDialogKeys clicks the default button of the dialog on top when Enter is pressed,
+// and the cancel button when Escape is pressed. It is called from Run after the shortcuts,
+// so the keys are only used when not used by the focused widget or a shortcut.
+func handleDialogKeys(gtx C) {
+	dialogMu.Lock()
+	var top *modal
+	if len(dialogs) > 0 {
+		top = dialogs[len(dialogs)-1]
+	}
+	dialogMu.Unlock()
+	if top == nil {
+		return
+	}
+	for {
+		ev, ok := gtx.Event(key.Filter{Name: key.NameEscape}, key.Filter{Name: key.NameReturn}, key.Filter{Name: key.NameEnter})
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+		if e.Name == key.NameEscape {
+			if top.escape != nil {
+				top.escape()
+			} else {
+				Hide()
+			}
+		} else if top.enter != nil {
+			top.enter()
+		}
+		gtx.Execute(op.InvalidateCmd{})
+		// The next key is for the new dialog on top
+		break
+	}
+}
+
+// dialogButton makes a button closing the dialog with the button number and the value,
+// after calling the function given by the Do() option. A default button is disabled
+// while an edit above it has an invalid text.
+func dialogButton(b *ButtonDef, button int, isDefault bool, value func() any) layout.Widget {
+	// m is the dialog the button was last drawn in. It is closed, even if the
+	// function has shown another dialog on top of it, or has closed it already.
+	var m *modal
+	do := b.onUserChange
+	b.onUserChange = func() {
+		if do != nil {
+			do()
+		}
+		r := Result{Button: button}
+		if value != nil {
+			r.Value = value()
+		}
+		if m != nil {
+			closeDialog(m, r)
+		} else {
+			CloseModal(r)
+		}
+	}
+	w := dialogKeys(b, isDefault, button == Cancelled)
+	return func(gtx C) D {
+		if current != nil {
+			m = current
+		}
+		return w(gtx)
+	}
+}
+
+// dialogKeys draws a button of the dialog, clicked by the Enter key when it is the default
+// button, or by the Escape key when it is the cancel button.
+func dialogKeys(b *ButtonDef, isDefault bool, isCancel bool) layout.Widget {
+	return func(gtx C) D {
+		if current != nil && isDefault && current.invalid {
+			gtx = gtx.Disabled()
+		}
+		gtx = b.CheckDisabler(gtx)
+		if current != nil && gtx.Enabled() {
+			if isDefault {
+				current.enter = b.onUserChange
+			} else if isCancel {
+				current.escape = b.onUserChange
+			}
+		}
+		return b.Layout(gtx)
+	}
+}
+
+// DialogButton returns a text button closing the dialog, with the button number as result.
+// When the number is Cancelled, the button is also clicked by the Escape key.
+func DialogButton(th *Theme, label string, button int, options ...Option) layout.Widget {
+	options = append([]Option{Role(Canvas)}, options...)
+	return dialogButton(aButton(Text, th, label, options...), button, false, nil)
+}
+
+// DefaultButton returns a filled button closing the dialog, with the button number as result.
+// It is also clicked by the Enter key.
+func DefaultButton(th *Theme, label string, button int, options ...Option) layout.Widget {
+	return dialogButton(aButton(Contained, th, label, options...), button, true, nil)
+}
+
+// ConfirmDialog returns a dialog with a text and one button, that is the default button with
+// the result 1. The function on1 is called when it is clicked, and can be nil.
+// The dialog is closed by the button, so the function must not call Hide().
 func ConfirmDialog(th *Theme, heading string, text string, lbl1 string, on1 func()) layout.Widget {
-	return Container(th, TransparentSurface, 0, FlexInset, NoInset,
-		Dialog(th, PrimaryContainer,
-			Label(th, heading, Heading(), Middle()),
-			Label(th, text, Middle()),
-			Separator(th, 0, Pads(10)),
-			Row(th, nil, SpaceRightAdjust,
-				TextButton(th, lbl1, Do(on1)),
-			),
+	return Dialog(th, PrimaryContainer,
+		Label(th, heading, Heading(), Middle()),
+		Label(th, text, Middle()),
+		Separator(th, 0, Pads(10)),
+		Row(th, nil, SpaceRightAdjust,
+			DefaultButton(th, lbl1, 1, Do(on1)),
 		),
 	)
 }
 
+// YesNoDialog returns a dialog with a text and two buttons. The first button cancels the dialog,
+// also by the Escape key, and the second is the default button with the result 1. The functions
+// are called when the buttons are clicked, and can be nil. They must not call Hide().
 func YesNoDialog(th *Theme, heading string, text string, lbl1, lbl2 string, on1, on2 func()) layout.Widget {
 	return Dialog(th, PrimaryContainer,
 		Label(th, heading, Heading(), Middle()),
 		Label(th, text, Middle()),
 		Separator(th, 0, Pads(10)),
 		Row(th, nil, SpaceRightAdjust,
-			TextButton(th, lbl1, Do(on1)),
-			TextButton(th, lbl2, Do(on2)),
+			DialogButton(th, lbl1, Cancelled, Do(on1)),
+			DefaultButton(th, lbl2, 1, Do(on2)),
 		),
 	)
 }
 
 func Dialog(th *Theme, role UIRole, widgets ...Wid) Wid {
+	return sizedDialog(th, role, 0, widgets)
+}
+
+// sizedDialog is a dialog with room for widgets of the width, or th.DialogTextWidth when it is 0
+func sizedDialog(th *Theme, role UIRole, width unit.Sp, widgets []Wid) Wid {
 	return func(gtx C) D {
 		pt := Px(gtx, th.DialogPadding.Top)
 		pb := Px(gtx, th.DialogPadding.Bottom)
 		pl := Px(gtx, th.DialogPadding.Left)
 		pr := Px(gtx, th.DialogPadding.Right)
-		f := Min(1.0, float64(time.Since(dialogStartTime))/float64(time.Second/4))
+		// The dialog grows from the mouse position the first 250ms
+		f, startX, startY := 1.0, 0, 0
+		if current != nil {
+			f = Min(1.0, float64(gtx.Now.Sub(current.start))/float64(time.Second/4))
+			startX, startY = current.startX, current.startY
+		}
 		// Shade underlying form
 		// Draw surface all over the underlying form with the transparent surface color
 		outline := image.Rect(0, 0, gtx.Constraints.Max.X, gtx.Constraints.Max.Y)
@@ -70,7 +296,11 @@ func Dialog(th *Theme, role UIRole, widgets ...Wid) Wid {
 		ctx := gtx
 		ctx.Constraints.Min.Y = 0
 		// Margins left and right for a constant maximum dialog size
-		margin := Max(12, (ctx.Constraints.Max.X - pl - pr - Px(gtx, th.DialogTextWidth)))
+		w := width
+		if w == 0 {
+			w = th.DialogTextWidth
+		}
+		margin := Max(12, (ctx.Constraints.Max.X - pl - pr - Px(gtx, w)))
 		ctx.Constraints.Max.X = gtx.Constraints.Max.X - margin - pl - pr
 		calls := make([]op.CallOp, len(widgets))
 		dims := make([]D, len(widgets))
@@ -92,7 +322,7 @@ func Dialog(th *Theme, role UIRole, widgets ...Wid) Wid {
 		paint.Fill(gtx.Ops, th.Bg[role])
 		if f < 1.0 {
 			// While animating, no widgets are drawn, but we invalidate to force a new redraw
-			Invalidate()
+			gtx.Execute(op.InvalidateCmd{})
 		} else {
 			// Now do the actual drawing of the widgets, with offsets
 			y := pt
@@ -61,6 +61,7 @@ func DropDown(th *Theme, index *int, items []string, options ...Option) layout.W
 	for _, option := range options {
 		option.apply(b)
 	}
+	b.noUndo = b.untracked
 	if b.label == "" {
 		b.labelSize = 0
 	}
@@ -76,7 +77,7 @@ func (d *DropDownStyle) setLabelSize(w float32) {
 }
 
 func (d *DropDownStyle) Layout(gtx C) D {
-	d.CheckDisabler(gtx)
+	gtx = d.CheckDisabler(gtx)
 	d.maxIndex = len(d.items)
 	// Move to offset the external margin around both label and edit
 	defer op.Offset(image.Pt(
@@ -271,7 +272,9 @@ func (d *DropDownStyle) optionWidget(th *Theme, i int) func(gtx C) D {
 			switch ev.Kind {
 			case pointer.Release:
 				GuiLock.Lock()
+				old := *d.index
 				*d.index = i
+				d.record(d.index, old)
 				GuiLock.Unlock()
 				d.listVisible = false
 				d.itemHovered[i] = false
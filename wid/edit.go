@@ -4,6 +4,7 @@ package wid
 
 import (
 	"gioui.org/io/event"
+	"gioui.org/io/key"
 	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -14,6 +15,7 @@ import (
 	"gioui.org/widget"
 	"image"
 	"image/color"
+	"time"
 )
 
 type Value interface {
@@ -32,6 +34,34 @@ type EditDef struct {
 	labelSize       float32
 	borderThickness unit.Dp
 	wasFocused      bool
+	style           FieldStyle
+	labelAnim       VisibilityAnimation
+	leadIcon        fieldIcon
+	trailIcon       fieldIcon
+	focusEditor     bool
+	mask            rune
+	prefix          string
+	suffix          string
+	helper          string
+	showCounter     bool
+	inputMask       InputMask
+	rawValue        bool
+	maskLen         int
+	onChange        func(text string)
+	onSubmit        func(text string)
+	live            bool
+	debounce        time.Duration
+	changePending   bool
+	changeAt        time.Time
+	suggest         *suggestions
+	errText         string
+	errMsg          *string
+	undo            textHistory
+	validate        func(text string) string
+	validated       bool
+	validText       string
+	invalid         bool
+	touched         bool
 }
 
 func DefaultEditDef(th *Theme) EditDef {
@@ -81,6 +111,8 @@ func Edit(th *Theme, options ...any) layout.Widget {
 			v.apply(b)
 		} else if v, ok := option.(rune); ok {
 			e.Mask = v
+		} else if v, ok := option.(FieldStyle); ok {
+			e.style = v
 		}
 	}
 
@@ -89,26 +121,169 @@ func Edit(th *Theme, options ...any) layout.Widget {
 	}
 }
 
+// handleEvents processes the events from the editor, before it is laid out.
+// Change events are reported after the debounce interval, and submit events at once.
+func (e *EditDef) handleEvents(gtx C) {
+	e.handleSuggestions(gtx)
+	e.handleUndo(gtx)
+	for {
+		ev, ok := e.Editor.Update(gtx)
+		if !ok {
+			break
+		}
+		switch ev := ev.(type) {
+		case widget.ChangeEvent:
+			e.touched = true
+			e.applyMask()
+			e.changePending = true
+			e.changeAt = gtx.Now.Add(e.debounce)
+		case widget.SubmitEvent:
+			if e.changePending {
+				e.changed()
+			}
+			e.writeValue()
+			if e.onSubmit != nil {
+				e.onSubmit(ev.Text)
+			}
+			if current != nil {
+				current.submit = true
+			}
+		}
+	}
+	start, end := e.Selection()
+	e.undo.record(e.Text(), start, end, gtx.Now)
+	if e.changePending {
+		if gtx.Now.Before(e.changeAt) {
+			// Make sure we get a new frame when the debounce interval is finished
+			gtx.Execute(op.InvalidateCmd{At: e.changeAt})
+		} else {
+			e.changed()
+		}
+	}
+}
+
+// handleUndo replaces the undo in widget.Editor, which records every keystroke
+// and also the text set by the program. Ctrl+Z will undo, while Ctrl+Y and
+// Ctrl+Shift+Z will redo.
+func (e *EditDef) handleUndo(gtx C) {
+	for {
+		ev, ok := gtx.Event(
+			key.Filter{Focus: &e.Editor, Name: "Z", Required: key.ModShortcut, Optional: key.ModShift},
+			key.Filter{Focus: &e.Editor, Name: "Y", Required: key.ModShortcut},
+		)
+		if !ok {
+			break
+		}
+		k, ok := ev.(key.Event)
+		if !ok || k.State != key.Press || e.ReadOnly {
+			continue
+		}
+		dir := -1
+		if k.Name == "Y" || k.Modifiers.Contain(key.ModShift) {
+			dir = 1
+		}
+		if s, ok := e.undo.step(dir); ok {
+			e.SetText(s.text)
+			e.SetCaret(s.start, s.end)
+			// Update the variable and call the handlers as for a change made by the user,
+			// without waiting for the editor to report the new text
+			e.touched = true
+			e.changePending = true
+			e.changeAt = gtx.Now
+		}
+	}
+}
+
+// changed is called when the text has been modified and the debounce interval has passed.
+func (e *EditDef) changed() {
+	e.changePending = false
+	if e.live {
+		e.writeValue()
+	}
+	if e.suggest != nil {
+		e.suggest.textChanged(e.Text())
+	}
+	if e.onChange != nil {
+		e.onChange(e.Text())
+	}
+}
+
+// writeValue will update the variable bound to the edit, and call the
+// Do() handler if the value was changed.
+func (e *EditDef) writeValue() {
+	if e.value == nil || e.validate != nil && e.validate(e.Text()) != "" {
+		return
+	}
+	current := e.Text()
+	if e.inputMask != "" && e.rawValue {
+		current = e.inputMask.Strip(current)
+	}
+	GuiLock.Lock()
+	old := ValueToString(e.value, *e.DpNo)
+	oldValue := valueOf(e.value)
+	StringToValue(e.value, current)
+	changed := old != ValueToString(e.value, *e.DpNo)
+	if changed {
+		e.record(e.value, oldValue)
+	}
+	GuiLock.Unlock()
+	if changed && e.onUserChange != nil {
+		e.onUserChange()
+	}
+}
+
+// validateText checks the text when it has changed. The error message is shown
+// when the text has been modified by the user. An invalid text in a dialog
+// disables its default button.
+func (e *EditDef) validateText(gtx C) {
+	if e.validate == nil {
+		return
+	}
+	if t := e.Text(); !e.validated || t != e.validText {
+		msg := e.validate(t)
+		e.validated, e.validText, e.invalid = true, t, msg != ""
+		if e.touched || msg == "" {
+			e.errText = msg
+		}
+	}
+	if e.invalid && current != nil && gtx.Enabled() {
+		current.invalid = true
+	}
+}
+
 func (e *EditDef) updateValue(gtx C) {
 	if !gtx.Focused(&e.Editor) && e.value != nil {
 		current := e.Text()
 		if e.wasFocused {
 			// When the edit is loosing focus, we must update the underlying variable
-			GuiLock.Lock()
-			StringToValue(e.value, current)
-			GuiLock.Unlock()
+			e.writeValue()
 		} else {
 			// When the underlying variable changes, update the edit buffer
 			GuiLock.RLock()
-			e.SetText(ValueToString(e.value, *e.DpNo))
+			s := ValueToString(e.value, *e.DpNo)
 			GuiLock.RUnlock()
+			if e.inputMask != "" {
+				s = e.inputMask.Format(s, false)
+				e.maskLen = len([]rune(s))
+			}
+			if s != current {
+				e.SetText(s)
+				e.undo.reset(s)
+			}
 		}
 	}
 	e.wasFocused = gtx.Focused(&e.Editor)
 }
 
 func (e *EditDef) Layout(gtx C) D {
-	e.CheckDisabler(gtx)
+	gtx = e.CheckDisabler(gtx)
+	if current != nil && e.SingleLine {
+		// Enter in a dialog clicks the default button
+		e.Submit = true
+	}
+	if e.style != PlainField {
+		return e.layoutField(gtx)
+	}
 	// Precalculate margin and pdding in pixels
 	mt, mb, ml, mr := ScaleInset(gtx, e.margin)
 	pt, pb, pl, pr := ScaleInset(gtx, e.padding)
@@ -125,7 +300,9 @@ func (e *EditDef) Layout(gtx C) D {
 	paint.ColorOp{Color: e.th.SelectionColor}.Add(gtx.Ops)
 	selectionColorOps := macro.Stop()
 	// Update value
+	e.handleEvents(gtx)
 	e.updateValue(gtx)
+	e.validateText(gtx)
 	// Move to offset the outside margin
 	defer op.Offset(image.Pt(pl, pt)).Push(gtx.Ops).Pop()
 	// And reduce the size to make space for the padding and margin
@@ -163,13 +340,17 @@ func (e *EditDef) Layout(gtx C) D {
 	// Calculate border size and fill it with white/black when focused
 	border := image.Rectangle{Max: image.Pt(gtx.Constraints.Max.X+pl+pr, LblDim.Size.Y+pb+pt)}
 	rr := Min(Px(gtx, e.th.BorderCornerRadius), border.Max.Y/2)
-	if gtx.Focused(&e.Editor) {
+	if e.ReadOnly {
+		// Read-only edits have a tinted background to show that they can not be changed
+		paint.FillShape(gtx.Ops, e.th.Bg[SurfaceContainer], clip.UniformRRect(border, rr).Op(gtx.Ops))
+	} else if gtx.Focused(&e.Editor) {
 		paint.FillShape(gtx.Ops, e.th.Bg[Canvas], clip.UniformRRect(border, rr).Op(gtx.Ops))
 	}
 	// Move to get the padding needed
 	o = op.Offset(image.Pt(pl, pt)).Push(gtx.Ops)
 	// Now layout the editor itself
 	e.Editor.Layout(gtx, e.th.Shaper, *e.Font, e.th.TextSize*unit.Sp(e.FontScale), textColorOps, selectionColorOps)
+	e.layoutGhost(gtx, e.th.TextSize*unit.Sp(e.FontScale))
 	o.Pop()
 	// If the editor is empty, we display the hint text
 	if e.Editor.Len() == 0 {
@@ -178,7 +359,9 @@ func (e *EditDef) Layout(gtx C) D {
 	// Draw the border, if present
 	if e.borderThickness > 0 {
 		w := float32(Px(gtx, e.borderThickness))
-		if gtx.Focused(&e.Editor) {
+		if e.ReadOnly {
+			paintBorder(gtx, border, e.th.Fg[OutlineVariant], w, rr)
+		} else if gtx.Focused(&e.Editor) {
 			paintBorder(gtx, border, e.outlineColor, w*2, rr)
 		} else if e.hovered {
 			paintBorder(gtx, border, e.outlineColor, w*3/2, rr)
@@ -186,15 +369,24 @@ func (e *EditDef) Layout(gtx C) D {
 			paintBorder(gtx, border, e.Fg(), w, rr)
 		}
 	}
-	// Setup the pointer event handling
+	e.handleHover(gtx, border)
+	e.layoutSuggestions(gtx, border)
+	// Calculate size, including margins
+	dim := image.Pt(gtx.Constraints.Max.X, border.Max.Y+mb+mt)
+	return D{Size: dim}
+}
+
+// handleHover sets up the pointer event handling for the area given, and
+// updates the hovered state. A press inside the area will move focus to the editor.
+func (e *EditDef) handleHover(gtx C, area image.Rectangle) {
 	defer pointer.PassOp{}.Push(gtx.Ops).Pop()
-	eventArea := clip.Rect(border).Push(gtx.Ops)
+	eventArea := clip.Rect(area).Push(gtx.Ops)
 	event.Op(gtx.Ops, e)
 	eventArea.Pop()
 	for {
 		event, ok := gtx.Event(pointer.Filter{
 			Target: e,
-			Kinds:  pointer.Enter | pointer.Leave,
+			Kinds:  pointer.Enter | pointer.Leave | pointer.Press,
 		})
 		if !ok {
 			break
@@ -208,12 +400,12 @@ func (e *EditDef) Layout(gtx C) D {
 			e.hovered = false
 		case pointer.Enter:
 			e.hovered = true
+		case pointer.Press:
+			if gtx.Enabled() && !gtx.Focused(&e.Editor) {
+				gtx.Execute(key.FocusCmd{Tag: &e.Editor})
+			}
 		}
 	}
-
-	// Calculate size, including margins
-	dim := image.Pt(gtx.Constraints.Max.X, border.Max.Y+mb+mt)
-	return D{Size: dim}
 }
 
 // EditOption is options specific to Edits
@@ -226,6 +418,56 @@ func Var[V Value](s *V) EditOption {
 	}
 }
 
+// OnChange is an option setting a function that is called each time the text is modified.
+// If a debounce interval is given, it is called when no changes are done in the interval.
+func OnChange(f func(text string)) EditOption {
+	return func(w *EditDef) {
+		w.onChange = f
+	}
+}
+
+// OnSubmit is an option setting a function that is called when Enter is pressed.
+// The bound variable is updated before the function is called.
+func OnSubmit(f func(text string)) EditOption {
+	return func(w *EditDef) {
+		w.onSubmit = f
+		w.Submit = true
+	}
+}
+
+// Validate is an option giving a function that checks the text. It returns an error message,
+// or "" when the text is valid. The message is shown below text fields after the text is modified.
+// An invalid text is not written to the bound variable, and disables the default button of a dialog.
+func Validate(f func(text string) string) EditOption {
+	return func(w *EditDef) {
+		w.validate = f
+	}
+}
+
+// Debounce is an option that delays the OnChange callback and live updates
+// until the user has stopped typing for the given time.
+func Debounce(d time.Duration) EditOption {
+	return func(w *EditDef) {
+		w.debounce = d
+	}
+}
+
+// Live is an option that writes the text to the bound variable at each change,
+// and not only when the edit loses focus.
+func Live() EditOption {
+	return func(w *EditDef) {
+		w.live = true
+	}
+}
+
+// ReadOnly is an option that makes the text impossible to change. The edit can still
+// get focus, and the text can be selected and copied.
+func ReadOnly() EditOption {
+	return func(e *EditDef) {
+		e.ReadOnly = true
+	}
+}
+
 func (e *EditDef) setBorder(w unit.Dp) {
 	e.borderThickness = w
 }
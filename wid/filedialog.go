@@ -0,0 +1,790 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// FileFilter is a named list of patterns for the files shown in a file dialog, like
+// FileFilter{"Go files", []string{"*.go"}}. The patterns are matched by path.Match.
+type FileFilter struct {
+	Name     string
+	Patterns []string
+}
+
+// fileEntry is a file or directory in the table of a file dialog
+type fileEntry struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+	click   gesture.Click
+}
+
+// treeNode is a directory in the tree pane of a file dialog
+type treeNode struct {
+	path  string
+	depth int
+}
+
+// Columns of the file table, used for sorting
+const (
+	sortName = iota
+	sortSize
+	sortTime
+)
+
+// FileDialogDef is a dialog for choosing a file to open or save. It has a tree of directories,
+// a table of the files in the selected directory, and an edit with the file name.
+type FileDialogDef struct {
+	Base
+	fsys    fs.FS
+	save    bool
+	filters []FileFilter
+	filter  int
+	hidden  bool
+	// dir is the directory shown, where "." is the root of the file system
+	dir  string
+	name string
+	// entries are the directories and files in dir, read with the filter and hidden flag in loaded
+	entries  []*fileEntry
+	loaded   string
+	selected int
+	sortBy   int
+	reverse  bool
+	// subdirs are the directories in each directory read for the tree pane
+	subdirs  map[string][]string
+	expanded map[string]bool
+	// found is the path last looked up by ready(), and exists is true when it was found
+	found  string
+	exists bool
+	// The clicks are kept between frames, while the entries and tree nodes may be rebuilt
+	treeClicks  map[string]*gesture.Click
+	crumbClicks []gesture.Click
+	headClicks  [3]gesture.Click
+	tree, table ListStyle
+	dialog      layout.Widget
+	// modal is the dialog in the stack of dialogs, and confirm gives the result of the
+	// dialog asking to replace a file, for the path in replace
+	modal   *modal
+	confirm <-chan Result
+	replace string
+}
+
+// FileDialogOption is options specific to file dialogs
+type FileDialogOption func(*FileDialogDef)
+
+var folderIcon, folderOpenIcon, fileIcon, chevronIcon, expandIcon *Icon
+
+// SaveMode is an option making a file dialog for saving a file. The file does not have to exist,
+// and the user is asked to confirm replacing a file that exists.
+func SaveMode() FileDialogOption {
+	return func(f *FileDialogDef) {
+		f.save = true
+	}
+}
+
+// Filters is an option giving the filters selected by the dropdown of a file dialog.
+// The first filter is used when the dialog is shown, and the default shows all files.
+func Filters(filters ...FileFilter) FileDialogOption {
+	return func(f *FileDialogDef) {
+		f.filters = filters
+	}
+}
+
+// StartDir is an option giving the directory shown when a file dialog is opened the first time
+func StartDir(dir string) FileDialogOption {
+	return func(f *FileDialogDef) {
+		f.dir = dir
+	}
+}
+
+// FileName is an option giving the initial file name of a file dialog
+func FileName(name string) FileDialogOption {
+	return func(f *FileDialogDef) {
+		f.name = name
+	}
+}
+
+func (o FileDialogOption) apply(cfg interface{}) {
+	o(cfg.(*FileDialogDef))
+}
+
+// FileDialog returns a dialog for choosing a file in the file system. Show it with ShowModal, and
+// the value of the result is the path of the file, in the slash separated form used by io/fs.
+// Use os.DirFS("/") or os.DirFS("C:/") for the disk, and filepath.FromSlash on the path.
+// The dialog keeps the directory, filter and sort order when it is shown again.
+func FileDialog(th *Theme, fsys fs.FS, heading string, options ...Option) layout.Widget {
+	return newFileDialog(th, fsys, heading, options).Layout
+}
+
+func newFileDialog(th *Theme, fsys fs.FS, heading string, options []Option) *FileDialogDef {
+	f := &FileDialogDef{
+		Base: Base{
+			th:        th,
+			role:      Canvas,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		fsys:       fsys,
+		dir:        ".",
+		selected:   -1,
+		subdirs:    map[string][]string{},
+		expanded:   map[string]bool{},
+		treeClicks: map[string]*gesture.Click{},
+	}
+	for _, option := range options {
+		option.apply(f)
+	}
+	if len(f.filters) == 0 {
+		f.filters = []FileFilter{{Name: "All files", Patterns: []string{"*"}}}
+	}
+	names := make([]string, len(f.filters))
+	for i, filter := range f.filters {
+		names[i] = filter.Name
+	}
+	f.tree = ListStyle{
+		list:           &layout.List{Axis: layout.Vertical},
+		theme:          th,
+		VScrollBar:     MakeScrollbarStyle(th),
+		HScrollBar:     MakeScrollbarStyle(th),
+		AnchorStrategy: Occupy,
+	}
+	f.table = f.tree
+	f.table.list = &layout.List{Axis: layout.Vertical}
+	dir := path.Clean(strings.TrimPrefix(f.dir, "/"))
+	if !fs.ValidPath(dir) {
+		dir = "."
+	}
+	f.setDir(dir)
+
+	action := "Open"
+	if f.save {
+		action = "Save"
+	}
+	ok := dialogKeys(aButton(Contained, th, action, Do(f.accept)), true, false)
+	okButton := layout.Widget(func(gtx C) D {
+		if !f.ready() {
+			gtx = gtx.Disabled()
+		}
+		return ok(gtx)
+	})
+	row := Row(th, nil, []float32{0.3, 0.3, 0.4},
+		DropDown(th, &f.filter, names, Untracked()),
+		Checkbox(th, "Hidden files", Bool(&f.hidden), Untracked()),
+		Row(th, nil, SpaceRightAdjust, DialogButton(th, "Cancel", Cancelled), okButton),
+	)
+	bottom := func(gtx C) D {
+		// The widths of the row are fractions of the minimum width
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return row(gtx)
+	}
+	f.dialog = sizedDialog(th, PrimaryContainer, th.TextSize*48, []Wid{
+		Label(th, heading, Heading(), Middle()),
+		f.layoutCrumbs,
+		f.layoutPanes,
+		Edit(th, &f.name, OutlinedField, Lbl("File name"), Validate(validFileName), Live(), Untracked()),
+		Separator(th, 0, Pads(10)),
+		bottom,
+	})
+	return f
+}
+
+// validFileName returns an error message when the file name is empty or not a valid path
+func validFileName(s string) string {
+	if s == "" {
+		return "The file name must be given"
+	}
+	if !fs.ValidPath(s) {
+		return "Not a valid file name"
+	}
+	return ""
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// setDir shows the directory, and expands it and its parents in the tree
+func (f *FileDialogDef) setDir(dir string) {
+	f.dir = dir
+	f.expanded["."] = true
+	for p := dir; p != "."; p = path.Dir(p) {
+		f.expanded[p] = true
+	}
+	f.table.list.Position = layout.Position{}
+}
+
+// setName sets the file name shown in the edit
+func (f *FileDialogDef) setName(name string) {
+	GuiLock.Lock()
+	f.name = name
+	GuiLock.Unlock()
+}
+
+// path returns the path of the file name in the edit
+func (f *FileDialogDef) path() string {
+	GuiLock.RLock()
+	defer GuiLock.RUnlock()
+	return path.Join(f.dir, f.name)
+}
+
+// matches is true when the file name matches a pattern of the current filter
+func (f *FileDialogDef) matches(name string) bool {
+	for _, pattern := range f.filters[Clamp(f.filter, 0, len(f.filters)-1)].Patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the directory when it, the filter or the hidden flag has changed
+func (f *FileDialogDef) load() {
+	GuiLock.RLock()
+	loaded := fmt.Sprintf("%s|%d|%v", f.dir, f.filter, f.hidden)
+	GuiLock.RUnlock()
+	if loaded == f.loaded {
+		return
+	}
+	f.loaded = loaded
+	f.entries, f.selected, f.found = nil, -1, ""
+	f.subdirs = map[string][]string{}
+	list, _ := fs.ReadDir(f.fsys, f.dir)
+	for _, d := range list {
+		if !f.hidden && isHidden(d.Name()) || !d.IsDir() && !f.matches(d.Name()) {
+			continue
+		}
+		e := &fileEntry{name: d.Name(), dir: d.IsDir()}
+		if info, err := d.Info(); err == nil {
+			e.size, e.modTime = info.Size(), info.ModTime()
+		}
+		f.entries = append(f.entries, e)
+	}
+	f.sort()
+}
+
+// sort orders the entries by the column sortBy, with the directories first
+func (f *FileDialogDef) sort() {
+	var sel *fileEntry
+	if f.selected >= 0 && f.selected < len(f.entries) {
+		sel = f.entries[f.selected]
+	}
+	sort.SliceStable(f.entries, func(i, j int) bool {
+		a, b := f.entries[i], f.entries[j]
+		if a.dir != b.dir {
+			return a.dir
+		}
+		if f.reverse {
+			a, b = b, a
+		}
+		switch {
+		case f.sortBy == sortSize && a.size != b.size:
+			return a.size < b.size
+		case f.sortBy == sortTime && !a.modTime.Equal(b.modTime):
+			return a.modTime.Before(b.modTime)
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+	for i, e := range f.entries {
+		if e == sel {
+			f.selected = i
+		}
+	}
+}
+
+// subdirsOf returns the names of the directories in the directory
+func (f *FileDialogDef) subdirsOf(dir string) []string {
+	if s, ok := f.subdirs[dir]; ok {
+		return s
+	}
+	var s []string
+	list, _ := fs.ReadDir(f.fsys, dir)
+	for _, d := range list {
+		if d.IsDir() && (f.hidden || !isHidden(d.Name())) {
+			s = append(s, d.Name())
+		}
+	}
+	f.subdirs[dir] = s
+	return s
+}
+
+// treeNodes appends the directory, and the directories below it when it is expanded
+func (f *FileDialogDef) treeNodes(dir string, depth int, nodes []treeNode) []treeNode {
+	nodes = append(nodes, treeNode{path: dir, depth: depth})
+	if f.expanded[dir] {
+		for _, s := range f.subdirsOf(dir) {
+			nodes = f.treeNodes(path.Join(dir, s), depth+1, nodes)
+		}
+	}
+	return nodes
+}
+
+// ready is true when the file can be opened or saved. A file to open must exist.
+func (f *FileDialogDef) ready() bool {
+	p := f.path()
+	if p != f.found {
+		_, err := fs.Stat(f.fsys, p)
+		f.found, f.exists = p, err == nil
+	}
+	return f.save || f.exists
+}
+
+// accept closes the dialog with the path of the file name as result. A directory is opened instead,
+// and the user must confirm replacing an existing file in save mode.
+func (f *FileDialogDef) accept() {
+	p := f.path()
+	info, err := fs.Stat(f.fsys, p)
+	switch {
+	case err == nil && info.IsDir():
+		f.setDir(p)
+		f.setName("")
+	case err == nil && f.save:
+		f.replace = p
+		f.confirm = ShowModal(Dialog(f.th, PrimaryContainer,
+			Label(f.th, "Replace file?", Heading(), Middle()),
+			Label(f.th, path.Base(p)+" already exists. Do you want to replace it?", Middle()),
+			Separator(f.th, 0, Pads(10)),
+			Row(f.th, nil, SpaceRightAdjust,
+				DialogButton(f.th, "Cancel", Cancelled),
+				DefaultButton(f.th, "Replace", 1),
+			),
+		))
+	case err == nil || f.save:
+		closeDialog(f.modal, Result{Button: 1, Value: p})
+	}
+}
+
+// checkConfirm closes the dialog when the user has confirmed replacing the file
+func (f *FileDialogDef) checkConfirm() {
+	if f.confirm == nil {
+		return
+	}
+	select {
+	case res := <-f.confirm:
+		f.confirm = nil
+		if res.Button == 1 {
+			closeDialog(f.modal, Result{Button: 1, Value: f.replace})
+		}
+	default:
+	}
+}
+
+// open opens a directory of the table, or accepts a file
+func (f *FileDialogDef) open(i int) {
+	e := f.entries[i]
+	if e.dir {
+		f.setDir(path.Join(f.dir, e.name))
+		return
+	}
+	f.setName(e.name)
+	f.accept()
+}
+
+// selectEntry selects an entry of the table, and copies the name of a file to the edit
+func (f *FileDialogDef) selectEntry(i int) {
+	f.selected = i
+	if !f.entries[i].dir {
+		f.setName(f.entries[i].name)
+	}
+	pos := f.table.list.Position
+	if i < pos.First || i >= pos.First+pos.Count-1 {
+		f.table.list.ScrollTo(Max(0, i-pos.Count/2))
+	}
+}
+
+// handleKeys moves the selection with the arrow keys, opens the selection with Enter,
+// and goes to the parent directory with Backspace
+func (f *FileDialogDef) handleKeys(gtx C) {
+	for {
+		ev, ok := gtx.Event(
+			key.FocusFilter{Target: f},
+			key.Filter{Focus: f, Name: key.NameUpArrow},
+			key.Filter{Focus: f, Name: key.NameDownArrow},
+			key.Filter{Focus: f, Name: key.NameReturn},
+			key.Filter{Focus: f, Name: key.NameEnter},
+			key.Filter{Focus: f, Name: key.NameDeleteBackward},
+		)
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+		switch e.Name {
+		case key.NameUpArrow:
+			if f.selected > 0 {
+				f.selectEntry(f.selected - 1)
+			}
+		case key.NameDownArrow:
+			if f.selected < len(f.entries)-1 {
+				f.selectEntry(f.selected + 1)
+			}
+		case key.NameReturn, key.NameEnter:
+			if f.selected >= 0 {
+				f.open(f.selected)
+			} else {
+				f.accept()
+			}
+		case key.NameDeleteBackward:
+			if f.dir != "." {
+				f.setDir(path.Dir(f.dir))
+			}
+		}
+	}
+}
+
+// label returns the recorded text, truncated to the width
+func (f *FileDialogDef) label(gtx C, txt string, weight font.Weight, col color.NRGBA, maxX int) (op.CallOp, D) {
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(Max(0, maxX), inf)}
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	colorCall := m.Stop()
+	fnt := *f.Font
+	fnt.Weight = weight
+	m = op.Record(gtx.Ops)
+	d := widget.Label{MaxLines: 1}.Layout(c, f.th.Shaper, fnt, f.th.TextSize*unit.Sp(f.FontScale), txt, colorCall)
+	return m.Stop(), d
+}
+
+// drawAt adds the recorded call at the position
+func drawAt(gtx C, call op.CallOp, x, y int) {
+	o := op.Offset(image.Pt(x, y)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+}
+
+// drawIcon draws the icon with the size at the position
+func drawIcon(gtx C, ic *Icon, col color.NRGBA, x, y, size int) {
+	o := op.Offset(image.Pt(x, y)).Push(gtx.Ops)
+	c := gtx
+	c.Constraints = layout.Exact(image.Pt(size, size))
+	ic.Layout(c, col)
+	o.Pop()
+}
+
+// rowHeight is the height of the rows in the panes and the breadcrumb
+func (f *FileDialogDef) rowHeight(gtx C) int {
+	return Px(gtx, f.th.TextSize*unit.Sp(f.FontScale)*1.4) + Px(gtx, unit.Dp(8))
+}
+
+// layoutCrumbs draws the path of the directory, where each part can be clicked to go to it
+func (f *FileDialogDef) layoutCrumbs(gtx C) D {
+	parts := []string{"."}
+	if f.dir != "." {
+		for _, s := range strings.Split(f.dir, "/") {
+			parts = append(parts, path.Join(parts[len(parts)-1], s))
+		}
+	}
+	for len(f.crumbClicks) < len(parts) {
+		f.crumbClicks = append(f.crumbClicks, gesture.Click{})
+	}
+	for i := range parts {
+		for {
+			e, ok := f.crumbClicks[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindClick && i < len(parts)-1 {
+				f.setDir(parts[i])
+				gtx.Execute(op.InvalidateCmd{})
+			}
+		}
+	}
+	width, h := gtx.Constraints.Max.X, f.rowHeight(gtx)
+	iconSize := Px(gtx, unit.Dp(18))
+	calls := make([]op.CallOp, len(parts))
+	dims := make([]D, len(parts))
+	total := 0
+	for i, p := range parts {
+		name, weight, col := path.Base(p), font.Normal, f.th.Bg[Primary]
+		if p == "." {
+			name = "Root"
+		}
+		if i == len(parts)-1 {
+			weight, col = font.Medium, f.th.Fg[PrimaryContainer]
+		}
+		calls[i], dims[i] = f.label(gtx, name, weight, col, inf)
+		total += dims[i].Size.X + iconSize
+	}
+	defer clip.Rect{Max: image.Pt(width, h)}.Push(gtx.Ops).Pop()
+	// The end of a long path is shown
+	x := Min(0, width-total+iconSize)
+	for i := range parts {
+		w := dims[i].Size.X
+		drawAt(gtx, calls[i], x, (h-dims[i].Size.Y)/2)
+		if i < len(parts)-1 {
+			a := clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+w, h)}.Push(gtx.Ops)
+			f.crumbClicks[i].Add(gtx.Ops)
+			pointer.CursorPointer.Add(gtx.Ops)
+			a.Pop()
+			drawIcon(gtx, chevronIcon, f.th.Fg[PrimaryContainer], x+w, (h-iconSize)/2, iconSize)
+		}
+		x += w + iconSize
+	}
+	return D{Size: image.Pt(width, h)}
+}
+
+// pane draws the background and border of a pane, and returns the area inside the border
+func (f *FileDialogDef) pane(gtx C, r image.Rectangle) image.Rectangle {
+	rr, bw := Px(gtx, unit.Dp(4)), Max(1, Px(gtx, unit.Dp(1)))
+	paint.FillShape(gtx.Ops, f.th.Bg[Outline], clip.UniformRRect(r, rr).Op(gtx.Ops))
+	r = r.Inset(bw)
+	paint.FillShape(gtx.Ops, f.th.Bg[Canvas], clip.UniformRRect(r, rr).Op(gtx.Ops))
+	return r
+}
+
+// layoutPanes draws the tree of directories to the left, and the table of files to the right
+func (f *FileDialogDef) layoutPanes(gtx C) D {
+	f.handleKeys(gtx)
+	f.load()
+	width := gtx.Constraints.Max.X
+	height := Min(Px(gtx, unit.Dp(320)), gtx.Constraints.Max.Y*2/5)
+	gap := Px(gtx, unit.Dp(8))
+	treeW := width * 3 / 10
+	pad := Px(gtx, unit.Dp(4))
+
+	r := f.pane(gtx, image.Rect(0, pad, treeW, height-pad))
+	c := gtx
+	c.Constraints = layout.Exact(r.Size())
+	o := op.Offset(r.Min).Push(gtx.Ops)
+	cl := clip.Rect{Max: r.Size()}.Push(gtx.Ops)
+	f.layoutTree(c)
+	cl.Pop()
+	o.Pop()
+
+	r = f.pane(gtx, image.Rect(treeW+gap, pad, width, height-pad))
+	c.Constraints = layout.Exact(r.Size())
+	o = op.Offset(r.Min).Push(gtx.Ops)
+	cl = clip.Rect{Max: r.Size()}.Push(gtx.Ops)
+	f.layoutTable(c)
+	event.Op(gtx.Ops, f)
+	cl.Pop()
+	o.Pop()
+	return D{Size: image.Pt(width, height)}
+}
+
+// layoutTree draws the directories, indented by their depth. Clicking the arrow expands or
+// collapses a directory, and clicking the name shows it.
+func (f *FileDialogDef) layoutTree(gtx C) D {
+	nodes := f.treeNodes(".", 0, nil)
+	h := f.rowHeight(gtx)
+	iconSize := Px(gtx, unit.Dp(18))
+	return f.tree.Layout(gtx, len(nodes), nil, func(gtx C, i int) D {
+		n := nodes[i]
+		click := f.treeClicks[n.path]
+		if click == nil {
+			click = &gesture.Click{}
+			f.treeClicks[n.path] = click
+		}
+		indent := Px(gtx, unit.Dp(4)) + n.depth*Px(gtx, unit.Dp(16))
+		for {
+			e, ok := click.Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind != gesture.KindClick {
+				continue
+			}
+			if e.Position.X < indent+iconSize || n.path == f.dir {
+				f.expanded[n.path] = !f.expanded[n.path]
+			} else {
+				f.setDir(n.path)
+			}
+			gtx.Execute(op.InvalidateCmd{})
+		}
+		width := gtx.Constraints.Min.X
+		fg := f.th.Fg[Canvas]
+		if n.path == f.dir {
+			fg = f.th.Fg[SecondaryContainer]
+			paint.FillShape(gtx.Ops, f.th.Bg[SecondaryContainer], clip.Rect{Max: image.Pt(width, h)}.Op())
+		}
+		y := (h - iconSize) / 2
+		if len(f.subdirsOf(n.path)) > 0 {
+			ic := chevronIcon
+			if f.expanded[n.path] {
+				ic = expandIcon
+			}
+			drawIcon(gtx, ic, fg, indent, y, iconSize)
+		}
+		x := indent + iconSize
+		ic := folderIcon
+		if n.path == f.dir {
+			ic = folderOpenIcon
+		}
+		drawIcon(gtx, ic, fg, x, y, iconSize)
+		x += iconSize + Px(gtx, unit.Dp(4))
+		name := path.Base(n.path)
+		if n.path == "." {
+			name = "Root"
+		}
+		call, d := f.label(gtx, name, font.Normal, fg, width-x)
+		drawAt(gtx, call, x, (h-d.Size.Y)/2)
+		defer clip.Rect{Max: image.Pt(width, h)}.Push(gtx.Ops).Pop()
+		click.Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		return D{Size: image.Pt(width, h)}
+	})
+}
+
+// columns returns the x position of the size and modified columns, and their widths
+func (f *FileDialogDef) columns(gtx C, width int) (sizeX, timeX, sizeW, timeW int) {
+	sizeW, timeW = Px(gtx, unit.Dp(80)), Px(gtx, unit.Dp(136))
+	timeX = width - timeW
+	sizeX = timeX - sizeW - Px(gtx, unit.Dp(8))
+	return sizeX, timeX, sizeW, timeW
+}
+
+// layoutHeader draws the column headings. Clicking a heading sorts by the column,
+// and clicking it again reverses the order.
+func (f *FileDialogDef) layoutHeader(gtx C) D {
+	width, h := gtx.Constraints.Min.X, f.rowHeight(gtx)
+	sizeX, timeX, sizeW, timeW := f.columns(gtx, width)
+	pad := Px(gtx, unit.Dp(8))
+	x := [3]int{pad, sizeX, timeX}
+	w := [3]int{sizeX - pad, sizeW, timeW}
+	for i, title := range []string{"Name", "Size", "Modified"} {
+		for {
+			e, ok := f.headClicks[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindClick {
+				f.reverse = f.sortBy == i && !f.reverse
+				f.sortBy = i
+				f.sort()
+			}
+		}
+		if f.sortBy == i && f.reverse {
+			title += " ↓"
+		} else if f.sortBy == i {
+			title += " ↑"
+		}
+		call, d := f.label(gtx, title, font.Medium, f.th.Fg[Canvas], w[i])
+		tx := x[i]
+		if i == sortSize {
+			tx += w[i] - d.Size.X
+		}
+		drawAt(gtx, call, tx, (h-d.Size.Y)/2)
+		a := clip.Rect{Min: image.Pt(x[i], 0), Max: image.Pt(x[i]+w[i], h)}.Push(gtx.Ops)
+		f.headClicks[i].Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		a.Pop()
+	}
+	lw := Max(1, Px(gtx, unit.Dp(1)))
+	paint.FillShape(gtx.Ops, f.th.Bg[OutlineVariant], clip.Rect{Min: image.Pt(0, h-lw), Max: image.Pt(width, h)}.Op())
+	return D{Size: image.Pt(width, h)}
+}
+
+// layoutTable draws the directories and files in the directory. Clicking an entry selects it,
+// and double clicking opens it.
+func (f *FileDialogDef) layoutTable(gtx C) D {
+	h := f.rowHeight(gtx)
+	iconSize := Px(gtx, unit.Dp(18))
+	pad := Px(gtx, unit.Dp(8))
+	return f.table.Layout(gtx, len(f.entries), f.layoutHeader, func(gtx C, i int) D {
+		if i >= len(f.entries) {
+			return D{}
+		}
+		e := f.entries[i]
+		for {
+			ev, ok := e.click.Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind != gesture.KindClick {
+				continue
+			}
+			gtx.Execute(key.FocusCmd{Tag: f})
+			if ev.NumClicks > 1 {
+				f.open(i)
+				gtx.Execute(op.InvalidateCmd{})
+				return D{Size: image.Pt(gtx.Constraints.Min.X, h)}
+			}
+			f.selectEntry(i)
+		}
+		width := gtx.Constraints.Min.X
+		sizeX, timeX, sizeW, timeW := f.columns(gtx, width)
+		fg := f.th.Fg[Canvas]
+		if i == f.selected {
+			fg = f.th.Fg[SecondaryContainer]
+			paint.FillShape(gtx.Ops, f.th.Bg[SecondaryContainer], clip.Rect{Max: image.Pt(width, h)}.Op())
+		}
+		ic := fileIcon
+		if e.dir {
+			ic = folderIcon
+		}
+		drawIcon(gtx, ic, fg, pad, (h-iconSize)/2, iconSize)
+		x := pad + iconSize + Px(gtx, unit.Dp(4))
+		call, d := f.label(gtx, e.name, font.Normal, fg, sizeX-x-pad)
+		drawAt(gtx, call, x, (h-d.Size.Y)/2)
+		if !e.dir {
+			call, d = f.label(gtx, formatSize(e.size), font.Normal, fg, sizeW)
+			drawAt(gtx, call, sizeX+sizeW-d.Size.X, (h-d.Size.Y)/2)
+		}
+		if !e.modTime.IsZero() {
+			call, d = f.label(gtx, e.modTime.Format("2006-01-02 15:04"), font.Normal, fg, timeW)
+			drawAt(gtx, call, timeX, (h-d.Size.Y)/2)
+		}
+		defer clip.Rect{Max: image.Pt(width, h)}.Push(gtx.Ops).Pop()
+		e.click.Add(gtx.Ops)
+		return D{Size: image.Pt(width, h)}
+	})
+}
+
+// formatSize returns the size in bytes, kB, MB, GB or TB
+func formatSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, i := float64(n)/1024, 0
+	for v >= 1024 && i < 3 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %cB", v, "kMGT"[i])
+}
+
+// Layout draws the dialog, and reads the directory again when the dialog is shown again
+func (f *FileDialogDef) Layout(gtx C) D {
+	if current != nil {
+		f.modal = current
+		if current.start.Equal(gtx.Now) {
+			f.loaded = ""
+		}
+	}
+	f.checkConfirm()
+	return f.dialog(gtx)
+}
+
+func init() {
+	folderIcon, _ = NewIcon(icons.FileFolder)
+	folderOpenIcon, _ = NewIcon(icons.FileFolderOpen)
+	fileIcon, _ = NewIcon(icons.EditorInsertDriveFile)
+	chevronIcon, _ = NewIcon(icons.NavigationChevronRight)
+	expandIcon, _ = NewIcon(icons.NavigationExpandMore)
+}
@@ -0,0 +1,135 @@
+package wid
+
+import (
+	"image"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+var t0 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var fileSystem = fstest.MapFS{
+	"a.txt":         {Data: []byte("hello"), ModTime: t0.Add(time.Hour)},
+	"b.go":          {Data: make([]byte, 2048), ModTime: t0},
+	"C.go":          {Data: make([]byte, 10), ModTime: t0.Add(2 * time.Hour)},
+	".hidden":       {Data: []byte("x")},
+	".git/config":   {Data: []byte("")},
+	"src/main.go":   {Data: []byte("package main")},
+	"src/util/x.go": {Data: []byte("package util")},
+}
+
+func entryNames(f *FileDialogDef) string {
+	var s []string
+	for _, e := range f.entries {
+		s = append(s, e.name)
+	}
+	return strings.Join(s, ",")
+}
+
+// drawDialogs draws the dialogs shown, as done by Run
+func drawDialogs() {
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Now:         time.Now(),
+		Constraints: layout.Exact(image.Point{X: 800, Y: 600}),
+	}
+	layoutDialogs(gtx)
+}
+
+func TestFileDialogList(t *testing.T) {
+	th := NewTheme(gofont.Collection(), 14)
+	f := newFileDialog(th, fileSystem, "Open", []Option{Filters(
+		FileFilter{"Go files", []string{"*.go"}},
+		FileFilter{"All files", []string{"*"}},
+	)})
+	f.load()
+	if s := entryNames(f); s != "src,b.go,C.go" {
+		t.Errorf("Go files: %s", s)
+	}
+	f.filter = 1
+	f.load()
+	if s := entryNames(f); s != "src,a.txt,b.go,C.go" {
+		t.Errorf("All files: %s", s)
+	}
+	f.hidden = true
+	f.load()
+	if s := entryNames(f); s != ".git,src,.hidden,a.txt,b.go,C.go" {
+		t.Errorf("Hidden files: %s", s)
+	}
+	f.hidden = false
+	f.load()
+	f.sortBy = sortSize
+	f.sort()
+	if s := entryNames(f); s != "src,a.txt,C.go,b.go" {
+		t.Errorf("Sorted by size: %s", s)
+	}
+	f.reverse = true
+	f.sort()
+	if s := entryNames(f); s != "src,b.go,C.go,a.txt" {
+		t.Errorf("Reversed size: %s", s)
+	}
+	f.sortBy, f.reverse = sortTime, false
+	f.sort()
+	if s := entryNames(f); s != "src,b.go,a.txt,C.go" {
+		t.Errorf("Sorted by time: %s", s)
+	}
+	f.open(0)
+	f.load()
+	if s := entryNames(f); f.dir != "src" || s != "util,main.go" {
+		t.Errorf("Directory %s: %s", f.dir, s)
+	}
+	if n := f.treeNodes(".", 0, nil); len(n) != 3 || n[2].path != "src/util" || n[2].depth != 2 {
+		t.Errorf("Tree: %v", n)
+	}
+}
+
+func TestFileDialogReplace(t *testing.T) {
+	th := NewTheme(gofont.Collection(), 14)
+	f := newFileDialog(th, fileSystem, "Save", []Option{SaveMode(), StartDir("src"), FileName("main.go")})
+	r := ShowModal(f.Layout)
+	drawDialogs()
+	if !f.ready() {
+		t.Fatal("Not ready to save")
+	}
+	// Replacing an existing file must be confirmed
+	f.accept()
+	if len(dialogs) != 2 {
+		t.Fatalf("No confirmation, %d dialogs", len(dialogs))
+	}
+	Hide()
+	drawDialogs()
+	if len(dialogs) != 1 {
+		t.Fatalf("Cancel closed the file dialog, %d dialogs", len(dialogs))
+	}
+	f.accept()
+	CloseModal(Result{Button: 1})
+	// A dialog shown meanwhile is not closed
+	other := ShowModal(func(gtx C) D { return D{} })
+	drawDialogs()
+	select {
+	case res := <-r:
+		if res.Button != 1 || res.Value != "src/main.go" {
+			t.Errorf("Result %v", res)
+		}
+	default:
+		t.Fatal("The file dialog was not closed")
+	}
+	if len(dialogs) != 1 || dialogs[0].result != other {
+		t.Errorf("Wrong dialog closed")
+	}
+	Hide()
+	// A new file is saved without confirmation
+	f.setName("new.go")
+	r = ShowModal(f.Layout)
+	drawDialogs()
+	f.accept()
+	if res := <-r; res.Value != "src/new.go" || dialogShown() {
+		t.Errorf("Result %v", res)
+	}
+}
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"sync"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+)
+
+// Draft holds copies of variables edited in a form dialog. The widgets of the dialog are
+// bound to the copies, and the variables are only changed when the dialog is accepted.
+type Draft struct {
+	fields []draftField
+}
+
+type draftField struct {
+	ptr           any
+	reset, commit func()
+}
+
+var (
+	draftMu sync.Mutex
+	// drafts are the copies in the dialogs shown, where changes are not recorded in the undo history
+	drafts = map[any]bool{}
+)
+
+// Bind returns a copy of the variable, to be bound to a widget in a form dialog.
+// The copy is written back to the variable when the dialog is accepted.
+func Bind[T any](d *Draft, v *T) *T {
+	c := new(T)
+	f := draftField{
+		ptr: c,
+		reset: func() {
+			GuiLock.Lock()
+			*c = *v
+			GuiLock.Unlock()
+		},
+		commit: func() {
+			GuiLock.Lock()
+			old := *v
+			*v = *c
+			recordChange(v, old)
+			GuiLock.Unlock()
+		},
+	}
+	f.reset()
+	d.fields = append(d.fields, f)
+	return c
+}
+
+// Reset sets the copies to the values of the variables
+func (d *Draft) Reset() {
+	for _, f := range d.fields {
+		f.reset()
+	}
+}
+
+// Commit writes the copies to the variables
+func (d *Draft) Commit() {
+	for _, f := range d.fields {
+		f.commit()
+	}
+}
+
+// hold marks the copies as drafts while the dialog is shown
+func (d *Draft) hold() {
+	draftMu.Lock()
+	defer draftMu.Unlock()
+	for _, f := range d.fields {
+		drafts[f.ptr] = true
+	}
+}
+
+// release forgets the copies when the dialog is closed
+func (d *Draft) release() {
+	draftMu.Lock()
+	defer draftMu.Unlock()
+	for _, f := range d.fields {
+		delete(drafts, f.ptr)
+	}
+}
+
+func isDraft(ptr any) bool {
+	draftMu.Lock()
+	defer draftMu.Unlock()
+	return drafts[ptr]
+}
+
+// FormDialog returns a dialog with the widgets, and Cancel and OK buttons. The widgets are bound
+// to copies of variables made by Bind(), and the variables are changed when OK is clicked.
+// OK is disabled while an edit has an invalid text, given by the Validate() option.
+// The copies are reset each time the dialog is shown.
+func FormDialog(th *Theme, heading string, d *Draft, widgets ...layout.Widget) layout.Widget {
+	return formDialog(th, heading, d, nil, widgets)
+}
+
+func formDialog(th *Theme, heading string, d *Draft, value func() any, widgets []layout.Widget) layout.Widget {
+	rows := []layout.Widget{Label(th, heading, Heading(), Middle())}
+	rows = append(rows, widgets...)
+	rows = append(rows,
+		Separator(th, 0, Pads(10)),
+		Row(th, nil, SpaceRightAdjust,
+			DialogButton(th, "Cancel", Cancelled),
+			dialogButton(aButton(Contained, th, "OK", Do(d.Commit)), 1, true, value),
+		),
+	)
+	dlg := Dialog(th, PrimaryContainer, rows...)
+	return func(gtx C) D {
+		if current != nil && current.start.Equal(gtx.Now) {
+			// The dialog is shown again
+			d.Reset()
+			d.hold()
+			current.closed = d.release
+		}
+		return dlg(gtx)
+	}
+}
+
+// PromptDialog returns a dialog asking for a text. The validate function returns an error
+// message for invalid text, or "" when it is valid, and can be nil. When OK is clicked,
+// the text is written to the value, and is the value of the result.
+func PromptDialog(th *Theme, heading string, label string, value *string, validate func(text string) string) layout.Widget {
+	d := &Draft{}
+	v := Bind(d, value)
+	text := func() any {
+		GuiLock.RLock()
+		defer GuiLock.RUnlock()
+		return *v
+	}
+	return formDialog(th, heading, d, text, []layout.Widget{
+		Edit(th, v, OutlinedField, Lbl(label), Validate(validate), Live()),
+	})
+}
+
+// ChoiceDialog returns a dialog with radio buttons for the items. When OK is clicked,
+// the item selected is written to the value, and is the value of the result.
+// A long list of items is scrolled.
+func ChoiceDialog(th *Theme, heading string, value *string, items []string) layout.Widget {
+	d := &Draft{}
+	v := Bind(d, value)
+	radios := make([]layout.Widget, len(items))
+	for i, item := range items {
+		radios[i] = RadioButton(th, v, item, item)
+	}
+	list := List(th, Overlay, radios...)
+	choices := func(gtx C) D {
+		gtx.Constraints.Max.Y = Min(gtx.Constraints.Max.Y, Px(gtx, unit.Dp(300)))
+		return list(gtx)
+	}
+	selected := func() any {
+		GuiLock.RLock()
+		defer GuiLock.RUnlock()
+		return *v
+	}
+	return formDialog(th, heading, d, selected, []layout.Widget{choices})
+}
@@ -0,0 +1,610 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// ByteRange is a range of bytes highlighted in a HexView, like a field in a protocol header.
+// End is the offset after the last byte.
+type ByteRange struct {
+	Start, End int64
+	Role       UIRole
+}
+
+// HexViewDef shows binary data as offset, hex and ASCII columns.
+// Only the visible rows are read and drawn, so large files can be shown.
+type HexViewDef struct {
+	Base
+	data     *[]byte
+	reader   io.ReaderAt
+	size     int64
+	perRow   int
+	lines    int
+	editable bool
+	ranges   *[]ByteRange
+	// highlights is a copy of the ranges, made at each frame
+	highlights []ByteRange
+	list       layout.List
+	ls         ListStyle
+	// cache is a block of data read from the reader, starting at cacheAt
+	cache   []byte
+	cacheAt int64
+	charW   int
+	lineH   int
+	pl      int
+	// The selection is from anchor to caret, both inclusive
+	anchor, caret int64
+	// ascii is true when the ASCII pane is active, for keys and copying
+	ascii bool
+	// nibble is true when the first hex digit of a byte is typed
+	nibble   bool
+	dragging bool
+	focused  bool
+	scroll   bool
+	gotoText string
+	gotoEdit EditDef
+	textSize unit.Sp
+}
+
+// hexBlock is the size of the blocks read from an io.ReaderAt
+const hexBlock = 65536
+
+// HexOption is options specific to HexView
+type HexOption func(*HexViewDef)
+
+// BytesPerRow is an option setting the number of bytes shown on each row. Default is 16.
+func BytesPerRow(n int) HexOption {
+	return func(h *HexViewDef) {
+		h.perRow = Max(n, 1)
+	}
+}
+
+// HexLines is an option setting the number of rows shown below the bar.
+// By default, it fills the available height, or 20 rows in a list.
+func HexLines(n int) HexOption {
+	return func(h *HexViewDef) {
+		h.lines = n
+	}
+}
+
+// HexEditable is an option that allows the bytes to be changed by typing hex digits
+// in the hex pane, or characters in the ASCII pane. Data from an io.ReaderAt is never changed.
+func HexEditable() HexOption {
+	return func(h *HexViewDef) {
+		h.editable = true
+	}
+}
+
+// Highlights is an option giving ranges of bytes to highlight. The ranges are read
+// at every frame, so the program can change them.
+func Highlights(r *[]ByteRange) HexOption {
+	return func(h *HexViewDef) {
+		h.ranges = r
+	}
+}
+
+func (o HexOption) apply(cfg interface{}) {
+	o(cfg.(*HexViewDef))
+}
+
+// HexView returns a viewer for the bytes in data. Click or drag to select bytes, use Tab
+// to switch between the hex and ASCII panes, Ctrl+C to copy and Ctrl+G to go to an offset.
+func HexView(th *Theme, data *[]byte, options ...Option) layout.Widget {
+	h := newHexView(th, options)
+	h.data = data
+	return h.Layout
+}
+
+// HexReader returns a read-only viewer for size bytes from r, typically a large file.
+func HexReader(th *Theme, r io.ReaderAt, size int64, options ...Option) layout.Widget {
+	h := newHexView(th, options)
+	h.reader = r
+	h.size = size
+	h.editable = false
+	h.cacheAt = -1
+	return h.Layout
+}
+
+func newHexView(th *Theme, options []Option) *HexViewDef {
+	h := &HexViewDef{
+		Base: Base{
+			th:        th,
+			role:      Canvas,
+			padding:   th.DefaultPadding,
+			margin:    th.DefaultMargin,
+			Font:      &font.Font{Typeface: monoTypeface},
+			FontScale: 1.0,
+		},
+		perRow: 16,
+		list:   layout.List{Axis: layout.Vertical},
+	}
+	h.ls = ListStyle{
+		list:           &h.list,
+		theme:          th,
+		VScrollBar:     MakeScrollbarStyle(th),
+		HScrollBar:     MakeScrollbarStyle(th),
+		AnchorStrategy: Occupy,
+	}
+	for _, option := range options {
+		option.apply(h)
+	}
+	h.gotoEdit = barEdit(th, &h.gotoText, "Go to offset")
+	h.gotoEdit.onSubmit = h.gotoOffset
+	return h
+}
+
+// length returns the number of bytes
+func (h *HexViewDef) length() int64 {
+	if h.data == nil {
+		return h.size
+	}
+	GuiLock.RLock()
+	defer GuiLock.RUnlock()
+	return int64(len(*h.data))
+}
+
+// bytes returns the bytes from ofs to end, or less at the end of the data
+func (h *HexViewDef) bytes(ofs, end int64) []byte {
+	if h.data != nil {
+		GuiLock.RLock()
+		defer GuiLock.RUnlock()
+		end = Min(end, int64(len(*h.data)))
+		if ofs >= end {
+			return nil
+		}
+		return append([]byte(nil), (*h.data)[ofs:end]...)
+	}
+	var b []byte
+	for ofs < end && ofs < h.size {
+		if h.cacheAt < 0 || ofs < h.cacheAt || ofs >= h.cacheAt+int64(len(h.cache)) {
+			h.cacheAt = ofs / hexBlock * hexBlock
+			h.cache = append(h.cache[:0], make([]byte, hexBlock)...)
+			// Read errors are shown as missing data
+			n, _ := h.reader.ReadAt(h.cache, h.cacheAt)
+			h.cache = h.cache[:n]
+			if n == 0 {
+				break
+			}
+		}
+		n := Min(end, h.cacheAt+int64(len(h.cache))) - ofs
+		b = append(b, h.cache[ofs-h.cacheAt:ofs-h.cacheAt+n]...)
+		ofs += n
+	}
+	return b
+}
+
+// hexCol returns the column of the hex digits of byte j in a row.
+// The offset takes 10 columns, and there is an extra space after every 8 bytes.
+func (h *HexViewDef) hexCol(j int) int {
+	return 10 + 3*j + j/8
+}
+
+// asciiCol returns the column of the character for byte j in a row
+func (h *HexViewDef) asciiCol(j int) int {
+	return h.hexCol(h.perRow-1) + 4 + j
+}
+
+// selection returns the first and last selected byte
+func (h *HexViewDef) selection() (int64, int64) {
+	return Min(h.anchor, h.caret), Max(h.anchor, h.caret)
+}
+
+// moveTo moves the caret to ofs, extending the selection if extend is true.
+func (h *HexViewDef) moveTo(ofs int64, extend bool) {
+	ofs = Clamp(ofs, 0, Max(h.length()-1, 0))
+	h.caret = ofs
+	if !extend {
+		h.anchor = ofs
+	}
+	h.nibble = false
+	h.scroll = true
+}
+
+// gotoOffset moves to the offset given as a decimal number, or as hex with 0x prefix
+func (h *HexViewDef) gotoOffset(s string) {
+	ofs, err := strconv.ParseInt(strings.TrimSpace(s), 0, 64)
+	if err != nil {
+		return
+	}
+	h.moveTo(ofs, false)
+}
+
+// selectedText returns the selected bytes as hex, or as text if the ASCII pane is active
+func (h *HexViewDef) selectedText() string {
+	a, b := h.selection()
+	data := h.bytes(a, b+1)
+	if h.ascii {
+		return printable(data)
+	}
+	s := make([]string, len(data))
+	for i, v := range data {
+		s[i] = fmt.Sprintf("%02X", v)
+	}
+	return strings.Join(s, " ")
+}
+
+// printable returns the bytes as text, with a dot for each byte that is not printable ASCII
+func printable(data []byte) string {
+	b := make([]byte, len(data))
+	for i, v := range data {
+		b[i] = '.'
+		if v >= 32 && v < 127 {
+			b[i] = v
+		}
+	}
+	return string(b)
+}
+
+// setByte changes the byte at ofs in place, and records the old byte for undo
+func (h *HexViewDef) setByte(ofs int64, v byte) {
+	GuiLock.Lock()
+	defer GuiLock.Unlock()
+	if ofs >= int64(len(*h.data)) {
+		return
+	}
+	old := (*h.data)[ofs]
+	(*h.data)[ofs] = v
+	set := func(b byte) func() {
+		return func() {
+			if ofs < int64(len(*h.data)) {
+				(*h.data)[ofs] = b
+			}
+		}
+	}
+	h.recordEdit(h.data, set(old), set(v))
+}
+
+// edit handles typed text. Hex digits change one nibble at a time.
+func (h *HexViewDef) edit(s string) {
+	for _, r := range s {
+		cur := h.bytes(h.caret, h.caret+1)
+		if len(cur) == 0 {
+			return
+		}
+		if h.ascii {
+			if r < 32 || r >= 127 {
+				continue
+			}
+			h.setByte(h.caret, byte(r))
+		} else {
+			v, err := strconv.ParseUint(string(r), 16, 8)
+			if err != nil {
+				continue
+			}
+			if !h.nibble {
+				h.setByte(h.caret, byte(v)<<4|cur[0]&0x0f)
+				h.nibble = true
+				continue
+			}
+			h.setByte(h.caret, cur[0]&0xf0|byte(v))
+		}
+		if h.caret+1 < h.length() {
+			h.moveTo(h.caret+1, false)
+		} else {
+			h.anchor, h.nibble = h.caret, false
+		}
+	}
+}
+
+// offsetAt returns the byte at the position p relative to the top of the rows,
+// and true if it is in the ASCII pane.
+func (h *HexViewDef) offsetAt(p image.Point) (int64, bool) {
+	if h.lineH == 0 || h.charW == 0 {
+		return 0, false
+	}
+	pos := h.list.Position
+	y := p.Y + pos.Offset
+	row := pos.First + y/h.lineH
+	if y < 0 {
+		row = pos.First - 1
+	}
+	col := (p.X - h.pl) / h.charW
+	j, ascii := 0, col >= h.asciiCol(0)-1
+	if ascii {
+		j = col - h.asciiCol(0)
+	} else {
+		for j < h.perRow-1 && col >= h.hexCol(j+1)-1 {
+			j++
+		}
+	}
+	j = Clamp(j, 0, h.perRow-1)
+	return Max(int64(row), 0)*int64(h.perRow) + int64(j), ascii
+}
+
+func (h *HexViewDef) handleEvents(gtx C) {
+	size := h.length()
+	page := int64(Max(h.list.Position.Count-1, 1) * h.perRow)
+	for {
+		ev, ok := gtx.Event(
+			key.FocusFilter{Target: h},
+			key.Filter{Focus: h, Name: key.NameLeftArrow, Optional: key.ModShift},
+			key.Filter{Focus: h, Name: key.NameRightArrow, Optional: key.ModShift},
+			key.Filter{Focus: h, Name: key.NameUpArrow, Optional: key.ModShift},
+			key.Filter{Focus: h, Name: key.NameDownArrow, Optional: key.ModShift},
+			key.Filter{Focus: h, Name: key.NamePageUp, Optional: key.ModShift},
+			key.Filter{Focus: h, Name: key.NamePageDown, Optional: key.ModShift},
+			key.Filter{Focus: h, Name: key.NameHome, Optional: key.ModShift | key.ModShortcut},
+			key.Filter{Focus: h, Name: key.NameEnd, Optional: key.ModShift | key.ModShortcut},
+			key.Filter{Focus: h, Name: key.NameTab, Optional: key.ModShift},
+			key.Filter{Focus: h, Name: "C", Required: key.ModShortcut},
+			key.Filter{Focus: h, Name: "A", Required: key.ModShortcut},
+			key.Filter{Focus: h, Name: "G", Required: key.ModShortcut},
+			pointer.Filter{Target: h, Kinds: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel},
+		)
+		if !ok {
+			break
+		}
+		switch e := ev.(type) {
+		case key.FocusEvent:
+			h.focused = e.Focus
+			h.nibble = false
+		case key.EditEvent:
+			if h.editable && h.data != nil && gtx.Enabled() {
+				h.edit(e.Text)
+			}
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			shift := e.Modifiers.Contain(key.ModShift)
+			row := h.caret - h.caret%int64(h.perRow)
+			switch e.Name {
+			case key.NameLeftArrow:
+				h.moveTo(h.caret-1, shift)
+			case key.NameRightArrow:
+				h.moveTo(h.caret+1, shift)
+			case key.NameUpArrow:
+				h.moveTo(Max(h.caret-int64(h.perRow), h.caret%int64(h.perRow)), shift)
+			case key.NameDownArrow:
+				if h.caret+int64(h.perRow) < size {
+					h.moveTo(h.caret+int64(h.perRow), shift)
+				}
+			case key.NamePageUp:
+				h.moveTo(h.caret-page, shift)
+			case key.NamePageDown:
+				h.moveTo(h.caret+page, shift)
+			case key.NameHome:
+				if e.Modifiers.Contain(key.ModShortcut) {
+					row = 0
+				}
+				h.moveTo(row, shift)
+			case key.NameEnd:
+				if e.Modifiers.Contain(key.ModShortcut) {
+					h.moveTo(size-1, shift)
+				} else {
+					h.moveTo(row+int64(h.perRow)-1, shift)
+				}
+			case key.NameTab:
+				h.ascii = !h.ascii
+				h.nibble = false
+			case "C":
+				gtx.Execute(clipboard.WriteCmd{Type: "application/text", Data: io.NopCloser(strings.NewReader(h.selectedText()))})
+			case "A":
+				h.anchor, h.caret = 0, Max(size-1, 0)
+			case "G":
+				gtx.Execute(key.FocusCmd{Tag: &h.gotoEdit.Editor})
+			}
+		case pointer.Event:
+			ofs, ascii := h.offsetAt(e.Position.Round())
+			switch e.Kind {
+			case pointer.Press:
+				if e.Buttons != pointer.ButtonPrimary {
+					break
+				}
+				gtx.Execute(key.FocusCmd{Tag: h})
+				h.dragging = true
+				h.ascii = ascii
+				h.moveTo(ofs, e.Modifiers.Contain(key.ModShift))
+			case pointer.Drag:
+				if h.dragging {
+					h.moveTo(ofs, true)
+				}
+			case pointer.Release, pointer.Cancel:
+				h.dragging = false
+			}
+		}
+	}
+}
+
+// fill paints the background of the bytes from a to b (inclusive) in a row, in both panes.
+func (h *HexViewDef) fill(gtx C, a, b int, col color.NRGBA) {
+	paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(h.x(h.hexCol(a)), 0), Max: image.Pt(h.x(h.hexCol(b)+2), h.lineH)}.Op())
+	paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(h.x(h.asciiCol(a)), 0), Max: image.Pt(h.x(h.asciiCol(b)+1), h.lineH)}.Op())
+}
+
+// x returns the pixel position of a column
+func (h *HexViewDef) x(col int) int {
+	return h.pl + col*h.charW
+}
+
+// text draws s at the given column
+func (h *HexViewDef) text(gtx C, col int, s string, c color.NRGBA) {
+	if !gtx.Enabled() {
+		c = Disabled(c)
+	}
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: c}.Add(gtx.Ops)
+	colorCall := m.Stop()
+	o := op.Offset(image.Pt(h.x(col), 0)).Push(gtx.Ops)
+	lc := gtx
+	lc.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	widget.Label{MaxLines: 1}.Layout(lc, h.th.Shaper, *h.Font, h.textSize, s, colorCall)
+	o.Pop()
+}
+
+// row draws row i, with highlights, selection and caret below the text
+func (h *HexViewDef) row(gtx C, i int) D {
+	n := int64(h.perRow)
+	ofs := int64(i) * n
+	data := h.bytes(ofs, ofs+n)
+	size := image.Pt(Max(gtx.Constraints.Min.X, h.x(h.asciiCol(h.perRow))+h.pl), h.lineH)
+	last := ofs + int64(len(data)) - 1
+	for _, r := range h.highlights {
+		if a, b := Max(r.Start, ofs), Min(r.End-1, last); a <= b {
+			h.fill(gtx, int(a-ofs), int(b-ofs), MulAlpha(h.th.Bg[r.Role], 90))
+		}
+	}
+	if a, b := h.selection(); a < b || h.focused {
+		if a, b = Max(a, ofs), Min(b, last); a <= b {
+			h.fill(gtx, int(a-ofs), int(b-ofs), h.th.SelectionColor)
+		}
+	}
+	if h.focused && h.caret >= ofs && h.caret <= last {
+		// The caret is a frame around the byte in the active pane
+		j := int(h.caret - ofs)
+		r := image.Rect(h.x(h.hexCol(j)), 0, h.x(h.hexCol(j)+2), h.lineH)
+		if h.ascii {
+			r = image.Rect(h.x(h.asciiCol(j)), 0, h.x(h.asciiCol(j)+1), h.lineH)
+		}
+		paintBorder(gtx, r, h.th.Bg[Primary], float32(Max(1, Px(gtx, unit.Dp(1)))), 0)
+	}
+	var hex strings.Builder
+	for j, v := range data {
+		if j > 0 {
+			hex.WriteString(strings.Repeat(" ", h.hexCol(j)-h.hexCol(j-1)-2))
+		}
+		fmt.Fprintf(&hex, "%02X", v)
+	}
+	h.text(gtx, 0, fmt.Sprintf("%08X", ofs), MulAlpha(h.Fg(), 140))
+	h.text(gtx, h.hexCol(0), hex.String(), h.Fg())
+	h.text(gtx, h.asciiCol(0), printable(data), h.Fg())
+	return D{Size: size}
+}
+
+// status returns the text shown in the bar, with the caret position and selection
+func (h *HexViewDef) status() string {
+	a, b := h.selection()
+	s := fmt.Sprintf("Offset 0x%X (%d)", h.caret, h.caret)
+	if a < b {
+		s += fmt.Sprintf("  Selected %d bytes", b-a+1)
+	} else if v := h.bytes(h.caret, h.caret+1); len(v) == 1 {
+		s += fmt.Sprintf("  Value %d", v[0])
+	}
+	return s + fmt.Sprintf("  Size %d", h.length())
+}
+
+func (h *HexViewDef) layoutBar(gtx C) D {
+	gtx.Constraints.Min.Y = 0
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = Min(Px(gtx, unit.Dp(160)), gtx.Constraints.Max.X)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			return h.gotoEdit.Layout(gtx)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			m := op.Record(gtx.Ops)
+			paint.ColorOp{Color: h.Fg()}.Add(gtx.Ops)
+			colorCall := m.Stop()
+			o := op.Offset(image.Pt(Px(gtx, h.padding.Left), 0)).Push(gtx.Ops)
+			defer o.Pop()
+			return widget.Label{MaxLines: 1}.Layout(gtx, h.th.Shaper, h.th.DefaultFont, h.th.TextSize, h.status(), colorCall)
+		}))
+}
+
+// scrollToCaret makes sure the row with the caret is visible
+func (h *HexViewDef) scrollToCaret() {
+	p := &h.list.Position
+	r := int(h.caret / int64(h.perRow))
+	if r < p.First || r == p.First && p.Offset > 0 {
+		p.First, p.Offset = r, 0
+	} else if p.Count > 0 && r >= p.First+p.Count-1 {
+		p.First, p.Offset = r-Max(p.Count-2, 0), 0
+	}
+	h.scroll = false
+}
+
+// Layout handles keys and pointer, and draws the visible rows
+func (h *HexViewDef) Layout(gtx C) D {
+	h.handleEvents(gtx)
+	mt, mb, ml, mr := ScaleInset(gtx, h.margin)
+	pt, pb, pl, pr := ScaleInset(gtx, h.padding)
+	h.pl = pl
+	h.textSize = h.th.TextSize * unit.Sp(h.FontScale)
+	h.highlights = h.highlights[:0]
+	if h.ranges != nil {
+		GuiLock.RLock()
+		h.highlights = append(h.highlights, *h.ranges...)
+		GuiLock.RUnlock()
+	}
+	if h.scroll {
+		h.scrollToCaret()
+	}
+
+	// Find the size of the characters, which are all equal in a monospace font
+	m := op.Record(gtx.Ops)
+	lc := gtx
+	lc.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	cdims := widget.Label{MaxLines: 1}.Layout(lc, h.th.Shaper, *h.Font, h.textSize, "0000000000", op.CallOp{})
+	m.Stop()
+	h.charW, h.lineH = cdims.Size.X/10, cdims.Size.Y
+	width := Max(100, gtx.Constraints.Max.X-ml-mr)
+	if w := Px(gtx, h.width); w > 0 && w < width {
+		width = w
+	}
+	// The goto field and the status, laid out first to find the height of the rows
+	bc := gtx
+	bc.Constraints = layout.Constraints{Max: image.Pt(width-pl-pr, inf)}
+	m = op.Record(gtx.Ops)
+	barH := h.layoutBar(bc).Size.Y + pt
+	barCall := m.Stop()
+	height := gtx.Constraints.Max.Y - mt - mb
+	if h.lines > 0 || height >= inf {
+		height = Max(h.lines, 1)*h.lineH + barH + pb
+		if h.lines == 0 {
+			height = 20*h.lineH + barH + pb
+		}
+	}
+	defer op.Offset(image.Pt(ml, mt)).Push(gtx.Ops).Pop()
+	box := image.Rect(0, 0, width, height)
+	rr := Px(gtx, h.th.BorderCornerRadius)
+	paint.FillShape(gtx.Ops, h.th.Bg[SurfaceContainerLowest], clip.UniformRRect(box, rr).Op(gtx.Ops))
+
+	o := op.Offset(image.Pt(pl, pt)).Push(gtx.Ops)
+	barCall.Add(gtx.Ops)
+	o.Pop()
+
+	// The rows, with the list handling scrolling and scrollbars
+	view := image.Rect(0, barH, width, height-pb)
+	defer op.Offset(view.Min).Push(gtx.Ops).Pop()
+	cl := clip.Rect{Max: view.Size()}.Push(gtx.Ops)
+	lc.Constraints = layout.Exact(view.Size())
+	rows := int((h.length() + int64(h.perRow) - 1) / int64(h.perRow))
+	h.ls.Layout(lc, rows, nil, h.row)
+	cl.Pop()
+	// Scroll events must pass through to the list below
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	area := clip.Rect{Max: image.Pt(view.Dx()-Px(gtx, h.ls.VScrollBar.Width()), view.Dy())}.Push(gtx.Ops)
+	event.Op(gtx.Ops, h)
+	pointer.CursorText.Add(gtx.Ops)
+	area.Pop()
+	pass.Pop()
+
+	// Border, emphasized when focused
+	defer op.Offset(view.Min.Mul(-1)).Push(gtx.Ops).Pop()
+	bw := float32(Px(gtx, h.th.BorderThickness))
+	if h.focused {
+		paintBorder(gtx, box, h.th.Bg[Primary], bw*2, rr)
+	} else {
+		paintBorder(gtx, box, h.th.Fg[Outline], bw, rr)
+	}
+	return D{Size: image.Pt(width+ml+mr, height+mt+mb)}
+}
@@ -24,6 +24,8 @@ type LabelDef struct {
 	// MaxLines limits the number of lines. Zero means no limit.
 	MaxLines int
 	value    interface{}
+	// selectable is used instead of widget.Label when the text can be selected and copied
+	selectable *widget.Selectable
 }
 
 // LabelOption is options specific to Edits.
@@ -43,6 +45,14 @@ func Weight(weight font.Weight) LabelOption {
 	}
 }
 
+// Selectable is an option that makes it possible to select the label text with
+// the mouse or keyboard, and copy it with Ctrl+C.
+func Selectable() LabelOption {
+	return func(d *LabelDef) {
+		d.selectable = &widget.Selectable{}
+	}
+}
+
 func (e LabelOption) apply(cfg interface{}) {
 	e(cfg.(*LabelDef))
 }
@@ -99,8 +109,21 @@ func (w *LabelDef) Layout(gtx C) D {
 	// Macro for the text drawing color
 	colMacro := op.Record(gtx.Ops)
 	paint.ColorOp{Color: w.Fg()}.Add(gtx.Ops)
+	textColor := colMacro.Stop()
 	// Then lay out the text
-	dims := tl.Layout(c, w.th.Shaper, w.Font, unit.Sp(w.FontScale)*w.th.TextSize, str, colMacro.Stop())
+	var dims D
+	if w.selectable != nil {
+		w.selectable.Alignment = w.Alignment
+		w.selectable.MaxLines = w.MaxLines
+		if w.selectable.Text() != str {
+			w.selectable.SetText(str)
+		}
+		selMacro := op.Record(gtx.Ops)
+		paint.ColorOp{Color: w.th.SelectionColor}.Add(gtx.Ops)
+		dims = w.selectable.Layout(c, w.th.Shaper, w.Font, unit.Sp(w.FontScale)*w.th.TextSize, textColor, selMacro.Stop())
+	} else {
+		dims = tl.Layout(c, w.th.Shaper, w.Font, unit.Sp(w.FontScale)*w.th.TextSize, str, textColor)
+	}
 	dims.Size.X += pl + pr
 	dims.Size.Y += pb + pt
 	return dims
@@ -237,15 +237,23 @@ type ListStyle struct {
 	VScrollBar  ScrollbarStyle
 	HScrollBar  ScrollbarStyle
 	AnchorStrategy
+	// pos is set to the scroll position in pixels when it is not nil
+	pos *int
 }
 
 // Table makes a scrollable vertical list with a fixed header row
 func Table(th *Theme, a AnchorStrategy, heading layout.Widget, widgets ...layout.Widget) layout.Widget {
+	return table(th, a, nil, heading, widgets)
+}
+
+// table makes a vertical list that reports its scroll position to pos, when it is not nil
+func table(th *Theme, a AnchorStrategy, pos *int, heading layout.Widget, widgets []layout.Widget) layout.Widget {
 	listStyle := ListStyle{
 		list:           &layout.List{Axis: layout.Vertical},
 		VScrollBar:     MakeScrollbarStyle(th),
 		HScrollBar:     MakeScrollbarStyle(th),
 		AnchorStrategy: a,
+		pos:            pos,
 	}
 	listStyle.theme = th
 	return func(gtx C) D {
@@ -269,6 +277,12 @@ func List(th *Theme, a AnchorStrategy, widgets ...layout.Widget) layout.Widget {
 	return Table(th, a, nil, widgets...)
 }
 
+// ScrollList makes a vertical list that sets pos to its scroll position in pixels, 0 when
+// it is at the top. It is typically used with the ScrollPos option of a top app bar.
+func ScrollList(th *Theme, a AnchorStrategy, pos *int, widgets ...layout.Widget) layout.Widget {
+	return table(th, a, pos, nil, widgets)
+}
+
 // Layout the list and its scrollbar.
 func (l *ListStyle) Layout(gtx C, length int, header layout.Widget, w layout.ListElement) D {
 	// Determine how much space the scrollbar occupies.
@@ -310,6 +324,13 @@ func (l *ListStyle) Layout(gtx C, length int, header layout.Widget, w layout.Lis
 	}
 	listDims := l.list.Layout(c, length, w)
 	call := macro.Stop()
+	if l.pos != nil {
+		// Report the scroll position, like to a top app bar above the list
+		*l.pos = l.list.Position.Offset
+		if l.list.Position.First > 0 && length > 0 {
+			*l.pos += l.list.Position.First * l.list.Position.Length / length
+		}
+	}
 	l.VertTotal = listDims.Size.Y
 	if l.HorVisible && l.AnchorStrategy == Occupy {
 		listDims.Size.Y += hBarWidth
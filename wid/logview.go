@@ -0,0 +1,539 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"gioui.org/font"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// LogLevel is the severity of a line in a LogBuffer
+type LogLevel uint8
+
+const (
+	// LogDebug is for details only needed when debugging
+	LogDebug LogLevel = iota
+	// LogInfo is for normal messages
+	LogInfo
+	// LogWarning is for problems that do not stop the program
+	LogWarning
+	// LogError is for errors
+	LogError
+)
+
+var logLevelNames = []string{"Debug", "Info", "Warning", "Error"}
+
+// String returns the name of the level
+func (l LogLevel) String() string {
+	if int(l) < len(logLevelNames) {
+		return logLevelNames[l]
+	}
+	return fmt.Sprint(int(l))
+}
+
+// LogBuffer is a ring buffer of log lines. Lines can be added from any goroutine.
+// When the buffer is full, the oldest lines are dropped.
+type LogBuffer struct {
+	mu       sync.Mutex
+	lines    []logLine
+	capacity int
+	// total is the number of lines ever added. Line n is found at lines[n%capacity]
+	total int
+	// start is the first line kept after Clear()
+	start int
+	// pending is set when a redraw is requested, and cleared by the LogView
+	pending atomic.Bool
+}
+
+type logLine struct {
+	text  string
+	level LogLevel
+}
+
+// NewLogBuffer returns a buffer keeping the last capacity lines
+func NewLogBuffer(capacity int) *LogBuffer {
+	return &LogBuffer{capacity: Max(capacity, 1)}
+}
+
+// Add appends the text with the given level. Text with several lines is split into lines.
+func (b *LogBuffer) Add(level LogLevel, text string) {
+	text = strings.TrimSuffix(text, "\n")
+	b.mu.Lock()
+	for _, s := range strings.Split(text, "\n") {
+		l := logLine{text: strings.TrimSuffix(s, "\r"), level: level}
+		if len(b.lines) < b.capacity {
+			b.lines = append(b.lines, l)
+		} else {
+			b.lines[b.total%b.capacity] = l
+		}
+		b.total++
+	}
+	b.mu.Unlock()
+	// Only one redraw is requested per frame, even when thousands of lines are added
+	if b.pending.CompareAndSwap(false, true) && invalidate != nil {
+		Invalidate()
+	}
+}
+
+// Printf appends a formatted line with the given level
+func (b *LogBuffer) Printf(level LogLevel, format string, args ...any) {
+	b.Add(level, fmt.Sprintf(format, args...))
+}
+
+// Write makes the buffer an io.Writer, so it can be used with log.SetOutput().
+// Lines containing ERROR, WARN or DEBUG get the corresponding level, others are LogInfo.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	s := string(p)
+	level := LogInfo
+	switch {
+	case strings.Contains(s, "ERROR"):
+		level = LogError
+	case strings.Contains(s, "WARN"):
+		level = LogWarning
+	case strings.Contains(s, "DEBUG"):
+		level = LogDebug
+	}
+	b.Add(level, s)
+	return len(p), nil
+}
+
+var _ io.Writer = (*LogBuffer)(nil)
+
+// Clear removes all lines
+func (b *LogBuffer) Clear() {
+	b.mu.Lock()
+	b.start = b.total
+	b.mu.Unlock()
+	if invalidate != nil {
+		Invalidate()
+	}
+}
+
+// Len returns the number of lines in the buffer
+func (b *LogBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total - b.first()
+}
+
+// first returns the number of the oldest line kept. Must be called with b.mu locked.
+func (b *LogBuffer) first() int {
+	return Max(b.start, b.total-b.capacity)
+}
+
+// line returns line number n, if it is still in the buffer. Must be called with b.mu locked.
+func (b *LogBuffer) line(n int) (logLine, bool) {
+	if n < b.first() || n >= b.total {
+		return logLine{}, false
+	}
+	return b.lines[n%b.capacity], true
+}
+
+// LogViewDef shows the lines in a LogBuffer. Only the visible lines are drawn,
+// so it can show hundreds of thousands of lines.
+type LogViewDef struct {
+	Base
+	buf   *LogBuffer
+	roles map[LogLevel]UIRole
+	lines int
+	// ids are the numbers of the lines shown, i.e. the lines with the selected level or above
+	ids      []int
+	seen     int
+	level    int
+	shown    int
+	search   string
+	term     string
+	list     layout.List
+	ls       ListStyle
+	charW    int
+	lineH    int
+	viewW    int
+	textSize unit.Sp
+	// The selected lines are given by line numbers, so they stay selected when lines are added
+	anchor, caret int
+	dragging      bool
+	focused       bool
+	searchEdit    EditDef
+	levels        layout.Widget
+	buttons       []layout.Widget
+}
+
+// LogOption is options specific to LogView
+type LogOption func(*LogViewDef)
+
+// LevelRole is an option setting the role used for the text color of lines with the given level.
+// The defaults are Outline for LogDebug, Tertiary for LogWarning and Error for LogError.
+func LevelRole(level LogLevel, role UIRole) LogOption {
+	return func(c *LogViewDef) {
+		c.roles[level] = role
+	}
+}
+
+// LogLines is an option setting the number of lines shown below the bar.
+// By default, it fills the available height, or 20 lines in a list.
+func LogLines(n int) LogOption {
+	return func(c *LogViewDef) {
+		c.lines = n
+	}
+}
+
+func (o LogOption) apply(cfg interface{}) {
+	o(cfg.(*LogViewDef))
+}
+
+// LogView returns a viewer for the lines in the buffer. It follows the end of the log,
+// unless the user has scrolled up. Lines are selected by clicking and dragging,
+// and copied with Ctrl+C. The bar on top has a search field and a level filter.
+func LogView(th *Theme, buf *LogBuffer, options ...Option) layout.Widget {
+	c := &LogViewDef{
+		Base: Base{
+			th:        th,
+			role:      Canvas,
+			padding:   th.DefaultPadding,
+			margin:    th.DefaultMargin,
+			Font:      &font.Font{Typeface: monoTypeface},
+			FontScale: 1.0,
+		},
+		buf:    buf,
+		roles:  map[LogLevel]UIRole{LogDebug: Outline, LogWarning: Tertiary, LogError: Error},
+		list:   layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+		anchor: -1,
+		caret:  -1,
+	}
+	c.ls = ListStyle{
+		list:           &c.list,
+		theme:          th,
+		VScrollBar:     MakeScrollbarStyle(th),
+		HScrollBar:     MakeScrollbarStyle(th),
+		AnchorStrategy: Occupy,
+	}
+	for _, option := range options {
+		option.apply(c)
+	}
+	c.searchEdit = barEdit(th, &c.search, "Search")
+	c.searchEdit.onSubmit = func(string) { c.findNext(1) }
+	c.levels = DropDown(th, &c.level, logLevelNames, W(130), Untracked())
+	c.buttons = []layout.Widget{
+		TextButton(th, "Prev", Do(func() { c.findNext(-1) })),
+		TextButton(th, "Next", Do(func() { c.findNext(1) })),
+		TextButton(th, "Clear", Do(buf.Clear)),
+	}
+	return c.Layout
+}
+
+// update adds the new lines from the buffer to the ids, and removes the dropped lines.
+func (c *LogViewDef) update() {
+	GuiLock.RLock()
+	level := c.level
+	GuiLock.RUnlock()
+	if level != c.shown {
+		// The filter is changed, so all lines must be checked again
+		c.ids = c.ids[:0]
+		c.seen = 0
+		c.shown = level
+		c.list.Position.BeforeEnd = false
+	}
+	b := c.buf
+	b.mu.Lock()
+	first := b.first()
+	c.seen = Max(c.seen, first)
+	for ; c.seen < b.total; c.seen++ {
+		if int(b.lines[c.seen%b.capacity].level) >= level {
+			c.ids = append(c.ids, c.seen)
+		}
+	}
+	b.mu.Unlock()
+	if k := sort.SearchInts(c.ids, first); k > 0 {
+		c.ids = c.ids[k:]
+		// Keep the same lines in view when the user has scrolled up
+		c.list.Position.First = Max(0, c.list.Position.First-k)
+	}
+}
+
+// indexAt returns the index in ids of the line at y pixels below the top of the view.
+func (c *LogViewDef) indexAt(y float32) int {
+	if c.lineH == 0 || len(c.ids) == 0 {
+		return -1
+	}
+	p := c.list.Position
+	n := int(y) + p.Offset
+	i := p.First + n/c.lineH
+	if n < 0 {
+		i = p.First + (n-c.lineH+1)/c.lineH
+	}
+	return Clamp(i, 0, len(c.ids)-1)
+}
+
+// selection returns the first and last selected line numbers, or -1 when nothing is selected.
+func (c *LogViewDef) selection() (int, int) {
+	if c.anchor < 0 {
+		return -1, -1
+	}
+	return Min(c.anchor, c.caret), Max(c.anchor, c.caret)
+}
+
+// selectedText returns the text of the selected lines that are shown
+func (c *LogViewDef) selectedText() string {
+	a, b := c.selection()
+	var sb strings.Builder
+	c.buf.mu.Lock()
+	defer c.buf.mu.Unlock()
+	for _, id := range c.ids[sort.SearchInts(c.ids, a):] {
+		if id > b {
+			break
+		}
+		if l, ok := c.buf.line(id); ok {
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// contains checks if the text contains the search string, ignoring case
+func contains(text, search string) bool {
+	return search != "" && strings.Contains(strings.ToLower(text), strings.ToLower(search))
+}
+
+// findNext selects the next line (dir=1) or previous line (dir=-1) containing the search text,
+// and scrolls it into view.
+func (c *LogViewDef) findNext(dir int) {
+	GuiLock.RLock()
+	s := strings.TrimSpace(c.search)
+	GuiLock.RUnlock()
+	n := len(c.ids)
+	if s == "" || n == 0 {
+		return
+	}
+	start := c.list.Position.First - dir
+	if c.caret >= 0 {
+		start = sort.SearchInts(c.ids, c.caret)
+	}
+	c.buf.mu.Lock()
+	defer c.buf.mu.Unlock()
+	for k := 1; k <= n; k++ {
+		i := ((start+dir*k)%n + n) % n
+		if l, ok := c.buf.line(c.ids[i]); ok && contains(l.text, s) {
+			c.anchor, c.caret = c.ids[i], c.ids[i]
+			c.list.Position.First = Max(0, i-3)
+			c.list.Position.Offset = 0
+			c.list.Position.BeforeEnd = true
+			return
+		}
+	}
+}
+
+func (c *LogViewDef) handleEvents(gtx C) {
+	filters := []event.Filter{
+		key.FocusFilter{Target: c},
+		key.Filter{Focus: c, Name: "C", Required: key.ModShortcut},
+		key.Filter{Focus: c, Name: "A", Required: key.ModShortcut},
+		pointer.Filter{Target: c, Kinds: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel},
+	}
+	if c.anchor >= 0 {
+		// Escape clears the selection. Without a selection, it is left to a dialog.
+		filters = append(filters, key.Filter{Focus: c, Name: key.NameEscape})
+	}
+	for {
+		ev, ok := gtx.Event(filters...)
+		if !ok {
+			break
+		}
+		switch e := ev.(type) {
+		case key.FocusEvent:
+			c.focused = e.Focus
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			switch e.Name {
+			case "C":
+				if c.anchor >= 0 {
+					gtx.Execute(clipboard.WriteCmd{Type: "application/text", Data: io.NopCloser(strings.NewReader(c.selectedText()))})
+				}
+			case "A":
+				if len(c.ids) > 0 {
+					c.anchor, c.caret = c.ids[0], c.ids[len(c.ids)-1]
+				}
+			case key.NameEscape:
+				c.anchor, c.caret = -1, -1
+			}
+		case pointer.Event:
+			i := c.indexAt(e.Position.Y)
+			switch e.Kind {
+			case pointer.Press:
+				if !gtx.Enabled() || e.Buttons != pointer.ButtonPrimary {
+					break
+				}
+				gtx.Execute(key.FocusCmd{Tag: c})
+				c.dragging = true
+				if i < 0 {
+					break
+				}
+				if e.Modifiers.Contain(key.ModShift) && c.anchor >= 0 {
+					c.caret = c.ids[i]
+				} else {
+					c.anchor, c.caret = c.ids[i], c.ids[i]
+				}
+			case pointer.Drag:
+				if c.dragging && i >= 0 && c.anchor >= 0 {
+					c.caret = c.ids[i]
+				}
+			case pointer.Release, pointer.Cancel:
+				c.dragging = false
+			}
+		}
+	}
+}
+
+// lineColor returns the text color of a line with the given level
+func (c *LogViewDef) lineColor(gtx C, level LogLevel) op.CallOp {
+	col := c.Fg()
+	if r, ok := c.roles[level]; ok {
+		col = c.th.Bg[r]
+	}
+	if !gtx.Enabled() {
+		col = Disabled(col)
+	}
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	return m.Stop()
+}
+
+// row draws the line with index i in the ids
+func (c *LogViewDef) row(gtx C, i int) D {
+	pl := Px(gtx, c.padding.Left)
+	id := c.ids[i]
+	c.buf.mu.Lock()
+	l, _ := c.buf.line(id)
+	c.buf.mu.Unlock()
+	m := op.Record(gtx.Ops)
+	lc := gtx
+	lc.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	dims := widget.Label{MaxLines: 1}.Layout(lc, c.th.Shaper, *c.Font, c.textSize, l.text, c.lineColor(gtx, l.level))
+	call := m.Stop()
+	size := image.Pt(Max(c.viewW, dims.Size.X+2*pl), c.lineH)
+	if a, b := c.selection(); id >= a && id <= b {
+		paint.FillShape(gtx.Ops, c.th.SelectionColor, clip.Rect{Max: size}.Op())
+	}
+	// Search matches are highlighted. The font is monospace, so all characters have the same width.
+	if s := c.term; s != "" {
+		t := strings.ToLower(l.text)
+		n := utf8.RuneCountInString(s)
+		col := MulAlpha(c.th.Bg[Tertiary], 70)
+		for p := strings.Index(t, s); p >= 0; {
+			x := pl + utf8.RuneCountInString(t[:p])*c.charW
+			paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+n*c.charW, c.lineH)}.Op())
+			k := strings.Index(t[p+len(s):], s)
+			if k < 0 {
+				break
+			}
+			p += len(s) + k
+		}
+	}
+	o := op.Offset(image.Pt(pl, 0)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+	return D{Size: size}
+}
+
+func (c *LogViewDef) layoutBar(gtx C) D {
+	gtx.Constraints.Min.Y = 0
+	rigid := func(w layout.Widget) layout.FlexChild { return layout.Rigid(w) }
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return c.searchEdit.Layout(gtx)
+		}),
+		rigid(c.buttons[0]), rigid(c.buttons[1]), rigid(c.levels), rigid(c.buttons[2]))
+}
+
+// Layout handles selection and keys, and draws the visible lines
+func (c *LogViewDef) Layout(gtx C) D {
+	c.buf.pending.Store(false)
+	c.handleEvents(gtx)
+	c.update()
+	mt, mb, ml, mr := ScaleInset(gtx, c.margin)
+	pt, pb, pl, pr := ScaleInset(gtx, c.padding)
+	c.textSize = c.th.TextSize * unit.Sp(c.FontScale)
+	GuiLock.RLock()
+	c.term = strings.ToLower(strings.TrimSpace(c.search))
+	GuiLock.RUnlock()
+
+	// Find the size of the characters, which are all equal in a monospace font
+	m := op.Record(gtx.Ops)
+	lc := gtx
+	lc.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	cdims := widget.Label{MaxLines: 1}.Layout(lc, c.th.Shaper, *c.Font, c.textSize, "0000000000", op.CallOp{})
+	m.Stop()
+	c.charW, c.lineH = cdims.Size.X/10, cdims.Size.Y
+	width := Max(100, gtx.Constraints.Max.X-ml-mr)
+	if w := Px(gtx, c.width); w > 0 && w < width {
+		width = w
+	}
+	// The search and filter bar, laid out first to find the height of the rows
+	bc := gtx
+	bc.Constraints = layout.Constraints{Max: image.Pt(width-pl-pr, inf)}
+	m = op.Record(gtx.Ops)
+	barH := c.layoutBar(bc).Size.Y + pt
+	barCall := m.Stop()
+	height := gtx.Constraints.Max.Y - mt - mb
+	if c.lines > 0 || height >= inf {
+		height = Max(c.lines, 1)*c.lineH + barH + pb
+		if c.lines == 0 {
+			height = 20*c.lineH + barH + pb
+		}
+	}
+	defer op.Offset(image.Pt(ml, mt)).Push(gtx.Ops).Pop()
+	box := image.Rect(0, 0, width, height)
+	rr := Px(gtx, c.th.BorderCornerRadius)
+	paint.FillShape(gtx.Ops, c.th.Bg[SurfaceContainerLowest], clip.UniformRRect(box, rr).Op(gtx.Ops))
+
+	o := op.Offset(image.Pt(pl, pt)).Push(gtx.Ops)
+	barCall.Add(gtx.Ops)
+	o.Pop()
+
+	// The lines, with the list handling scrolling and scrollbars
+	view := image.Rect(0, barH, width, height-pb)
+	defer op.Offset(view.Min).Push(gtx.Ops).Pop()
+	cl := clip.Rect{Max: view.Size()}.Push(gtx.Ops)
+	lc.Constraints = layout.Exact(view.Size())
+	c.viewW = view.Dx() - Px(gtx, c.ls.VScrollBar.Width())
+	c.ls.Layout(lc, len(c.ids), nil, c.row)
+	cl.Pop()
+	// Scroll events must pass through to the list below
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	area := clip.Rect{Max: image.Pt(c.viewW, view.Dy())}.Push(gtx.Ops)
+	event.Op(gtx.Ops, c)
+	pointer.CursorText.Add(gtx.Ops)
+	area.Pop()
+	pass.Pop()
+
+	// Border, emphasized when focused
+	defer op.Offset(view.Min.Mul(-1)).Push(gtx.Ops).Pop()
+	bw := float32(Px(gtx, c.th.BorderThickness))
+	if c.focused {
+		paintBorder(gtx, box, c.th.Bg[Primary], bw*2, rr)
+	} else {
+		paintBorder(gtx, box, c.th.Fg[Outline], bw, rr)
+	}
+	return D{Size: image.Pt(width+ml+mr, height+mt+mb)}
+}
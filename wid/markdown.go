@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+)
+
+// MarkdownDef holds the setup for a markdown document
+type MarkdownDef struct {
+	Base
+	onLink func(url string)
+	// dir is the directory where image files are found
+	dir string
+}
+
+// MarkdownOption is options specific to Markdown
+type MarkdownOption func(*MarkdownDef)
+
+// OnLink is an option setting the function called when a link is clicked
+func OnLink(f func(url string)) MarkdownOption {
+	return func(m *MarkdownDef) {
+		m.onLink = f
+	}
+}
+
+// ImageDir is an option giving the directory where images are found.
+// The default is the current directory.
+func ImageDir(dir string) MarkdownOption {
+	return func(m *MarkdownDef) {
+		m.dir = dir
+	}
+}
+
+func (o MarkdownOption) apply(cfg interface{}) {
+	o(cfg.(*MarkdownDef))
+}
+
+var (
+	mdImage    = regexp.MustCompile(`^!\[([^\]]*)\]\(([^)\s]+)[^)]*\)$`)
+	mdBullet   = regexp.MustCompile(`^(\s*)([-*+])\s+(.*)$`)
+	mdNumbered = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	mdHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdRule     = regexp.MustCompile(`^([-*_])(\s*([-*_]))*$`)
+)
+
+// Markdown returns a scrollable list showing the markdown source.
+// Headings, paragraphs, emphasis, inline code, code blocks,
+// bullet and numbered lists, links, images and rules are supported.
+func Markdown(th *Theme, source string, options ...Option) layout.Widget {
+	m := &MarkdownDef{
+		Base: Base{
+			th:        th,
+			role:      Surface,
+			padding:   th.DefaultPadding,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+	}
+	for _, option := range options {
+		option.apply(m)
+	}
+	return List(th, Occupy, m.blocks(source)...)
+}
+
+// blocks splits the source into paragraphs, headings, list items etc.
+// and returns a widget for each of them.
+func (m *MarkdownDef) blocks(source string) []layout.Widget {
+	var widgets []layout.Widget
+	var para []string
+	var item []string
+	marker, level := "", 0
+	flush := func() {
+		if len(para) > 0 {
+			widgets = append(widgets, m.text(m.inline(strings.Join(para, " "))))
+			para = nil
+		}
+		if len(item) > 0 {
+			widgets = append(widgets, m.listItem(marker, level, m.inline(strings.Join(item, " "))))
+			item = nil
+		}
+	}
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRightFunc(lines[i], unicode.IsSpace)
+		t := strings.TrimSpace(line)
+		if strings.HasPrefix(t, "```") {
+			// Fenced code block, kept as it is
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, strings.TrimRightFunc(lines[i], unicode.IsSpace))
+			}
+			widgets = append(widgets, m.codeBlock(strings.Join(code, "\n")))
+			continue
+		}
+		if t == "" {
+			flush()
+			continue
+		}
+		if s := mdHeading.FindStringSubmatch(t); s != nil {
+			flush()
+			widgets = append(widgets, m.heading(len(s[1]), m.inline(s[2])))
+		} else if len(t) >= 3 && mdRule.MatchString(t) {
+			flush()
+			widgets = append(widgets, Separator(m.th, unit.Dp(1), Pad(m.padding)))
+		} else if s := mdImage.FindStringSubmatch(t); s != nil {
+			flush()
+			widgets = append(widgets, m.image(s[1], s[2]))
+		} else if s := mdBullet.FindStringSubmatch(line); s != nil {
+			flush()
+			marker, level, item = "•", len(s[1])/2, []string{s[3]}
+		} else if s := mdNumbered.FindStringSubmatch(line); s != nil {
+			flush()
+			marker, level, item = s[2], len(s[1])/2, []string{s[3]}
+		} else if len(item) > 0 && line != t {
+			// Indented lines continue the list item
+			item = append(item, t)
+		} else {
+			if len(item) > 0 {
+				flush()
+			}
+			para = append(para, t)
+		}
+	}
+	flush()
+	return widgets
+}
+
+// inline converts text with emphasis, code and links into spans.
+func (m *MarkdownDef) inline(s string) []Span {
+	var spans []Span
+	var bold, italic bool
+	var buf strings.Builder
+	style := func(sp Span) Span {
+		if bold {
+			sp = sp.Bold()
+		}
+		if italic {
+			sp = sp.Italic()
+		}
+		return sp
+	}
+	flush := func() {
+		if buf.Len() > 0 {
+			spans = append(spans, style(Txt(buf.String())))
+			buf.Reset()
+		}
+	}
+	isWord := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	// inWord is true when the underscore at i is inside a word, like in snake_case
+	inWord := func(i int) bool {
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[i+1:])
+		return isWord(before) && isWord(after)
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			buf.WriteByte(s[i+1])
+			i += 2
+		case strings.HasPrefix(s[i:], "**") || strings.HasPrefix(s[i:], "__"):
+			flush()
+			bold = !bold
+			i += 2
+		case c == '*' || (c == '_' && !inWord(i)):
+			flush()
+			italic = !italic
+			i++
+		case c == '`' && strings.IndexByte(s[i+1:], '`') >= 0:
+			flush()
+			n := strings.IndexByte(s[i+1:], '`')
+			spans = append(spans, Txt(s[i+1:i+1+n]).Mono().Highlight(SurfaceContainerHighest))
+			i += n + 2
+		case c == '[':
+			mid := strings.Index(s[i:], "](")
+			end := strings.IndexByte(s[i:], ')')
+			if mid < 0 || end < mid {
+				buf.WriteByte(c)
+				i++
+				break
+			}
+			flush()
+			url := s[i+mid+2 : i+end]
+			spans = append(spans, style(Link(s[i+1:i+mid], func() {
+				if m.onLink != nil {
+					m.onLink(url)
+				}
+			})))
+			i += end + 1
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return spans
+}
+
+// text returns a paragraph
+func (m *MarkdownDef) text(spans []Span, options ...Option) layout.Widget {
+	return RichText(m.th, spans, append([]Option{Pad(m.padding), FontSize(float32(m.FontScale))}, options...)...)
+}
+
+// heading returns a heading of level 1-6. The first levels use the same scale as Heading() and Large().
+func (m *MarkdownDef) heading(level int, spans []Span) layout.Widget {
+	scale := []float32{1.8, 1.3, 1.15, 1.0, 1.0, 1.0}[level-1]
+	for i := range spans {
+		spans[i] = spans[i].Bold()
+	}
+	return m.text(spans, FontSize(scale*float32(m.FontScale)))
+}
+
+// codeBlock returns the code in a monospace font on a darker background
+func (m *MarkdownDef) codeBlock(code string) layout.Widget {
+	p := m.padding
+	w := RichText(m.th, []Span{Txt(code).Mono()}, Bg(&m.th.Bg[SurfaceContainerHighest]), FontSize(float32(m.FontScale)))
+	return func(gtx C) D {
+		return p.Layout(gtx, w)
+	}
+}
+
+// listItem returns a list item with a hanging indent
+func (m *MarkdownDef) listItem(marker string, level int, spans []Span) layout.Widget {
+	body := m.text(spans)
+	mark := RichText(m.th, []Span{Txt(marker)}, Pad(m.padding), FontSize(float32(m.FontScale)), Right())
+	indent := m.th.TextSize * unit.Sp(m.FontScale)
+	return func(gtx C) D {
+		w := Px(gtx, indent*unit.Sp(2))
+		return layout.Flex{}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints = layout.Exact(image.Pt(w*(level+1), gtx.Constraints.Max.Y))
+				gtx.Constraints.Min.Y = 0
+				return mark(gtx)
+			}),
+			layout.Flexed(1, body),
+		)
+	}
+}
+
+// image returns the image from the file, or the alt text if the file can not be read.
+func (m *MarkdownDef) image(alt string, file string) layout.Widget {
+	if m.dir != "" && !filepath.IsAbs(file) {
+		file = filepath.Join(m.dir, file)
+	}
+	f, err := os.Open(file)
+	if err == nil {
+		defer func() { _ = f.Close() }()
+		if img, _, err := image.Decode(f); err == nil {
+			return Image(img, ScaleDown)
+		}
+	}
+	return m.text([]Span{Txt(alt).Italic()})
+}
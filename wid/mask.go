@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// InputMask is a pattern restricting what can be typed into an Edit.
+// Each character in the mask is one position in the text:
+//
+//	9  a digit
+//	a  a letter
+//	A  a letter, converted to upper case
+//	h  a hexadecimal digit, converted to lower case
+//	H  a hexadecimal digit, converted to upper case
+//	#  a letter or a digit
+//	?  any character
+//	\  the next character is a literal, even if it is one of the above
+//
+// All other characters are literals that are inserted automatically.
+// Typing the next literal will end a group of positions early, so "999.999.999.999"
+// accepts "10.0.0.1". If the mask ends with "...", the pattern in front of it is repeated.
+type InputMask string
+
+// runeKind tells where a rune in a formatted text comes from
+type runeKind uint8
+
+const (
+	// maskInput is a rune entered at an input position
+	maskInput runeKind = iota
+	// maskLiteral is a literal from the mask
+	maskLiteral
+	// maskGroupEnd is a literal ending a group of input positions early
+	maskGroupEnd
+)
+
+// Some predefined masks
+const (
+	MaskDate     InputMask = "99.99.9999"
+	MaskTime     InputMask = "99:99"
+	MaskIPv4     InputMask = "999.999.999.999"
+	MaskMAC      InputMask = "HH:HH:HH:HH:HH:HH"
+	MaskHexBytes InputMask = "HH ..."
+)
+
+// maskPos is one position in a parsed mask
+type maskPos struct {
+	r       rune
+	literal bool
+}
+
+// parse converts the mask string to a list of positions
+func (m InputMask) parse() (pos []maskPos, repeat bool) {
+	s := string(m)
+	if strings.HasSuffix(s, "...") {
+		repeat = true
+		s = strings.TrimSuffix(s, "...")
+	}
+	escaped := false
+	for _, r := range s {
+		if escaped {
+			pos = append(pos, maskPos{r: r, literal: true})
+			escaped = false
+		} else if r == '\\' {
+			escaped = true
+		} else {
+			pos = append(pos, maskPos{r: r, literal: !strings.ContainsRune("9aAhH#?", r)})
+		}
+	}
+	return pos, repeat
+}
+
+// accept checks if r is allowed at the given mask position, and returns it converted.
+func (p maskPos) accept(r rune) (rune, bool) {
+	switch p.r {
+	case '9':
+		return r, r >= '0' && r <= '9'
+	case 'a':
+		return r, unicode.IsLetter(r)
+	case 'A':
+		return unicode.ToUpper(r), unicode.IsLetter(r)
+	case 'h':
+		return unicode.ToLower(r), strings.ContainsRune("0123456789abcdefABCDEF", r)
+	case 'H':
+		return unicode.ToUpper(r), strings.ContainsRune("0123456789abcdefABCDEF", r)
+	case '#':
+		return r, unicode.IsLetter(r) || unicode.IsDigit(r)
+	case '?':
+		return r, true
+	}
+	return r, false
+}
+
+// apply walks the text and the mask in parallel. It returns the accepted runes, and
+// for each rune where it comes from. Invalid characters are dropped.
+// If grow is true, literals following the last character are appended.
+func (m InputMask) apply(s string, grow bool) (out []rune, kind []runeKind) {
+	pos, repeat := m.parse()
+	if len(pos) == 0 {
+		return []rune(s), make([]runeKind, utf8.RuneCountInString(s))
+	}
+	get := func(i int) (maskPos, bool) {
+		if i < len(pos) {
+			return pos[i], true
+		} else if repeat {
+			return pos[i%len(pos)], true
+		}
+		return maskPos{}, false
+	}
+	i := 0
+	for _, r := range s {
+		// Position and length before trying this rune, used when it is rejected
+		i0, n0 := i, len(out)
+		early := false
+		for {
+			p, ok := get(i)
+			if !ok {
+				break
+			}
+			if p.literal {
+				out = append(out, p.r)
+				kind = append(kind, maskLiteral)
+				i++
+				if r == p.r {
+					// The literal was typed by the user
+					if early {
+						kind[len(kind)-1] = maskGroupEnd
+					}
+					n0, i0 = len(out), i
+					break
+				}
+				continue
+			}
+			if c, ok := p.accept(r); ok {
+				out = append(out, c)
+				kind = append(kind, maskInput)
+				i++
+				n0, i0 = len(out), i
+				break
+			}
+			// Typing the next literal ends the group early, if something is entered in the group
+			if len(kind) > 0 && kind[len(kind)-1] == maskInput {
+				found := -1
+				for j := i + 1; j <= i+len(pos); j++ {
+					q, ok := get(j)
+					if !ok {
+						break
+					}
+					if q.literal {
+						if q.r == r {
+							found = j
+						}
+						break
+					}
+				}
+				if found >= 0 {
+					i, early = found, true
+					continue
+				}
+			}
+			break
+		}
+		// Remove literals inserted in front of a rejected rune
+		out, kind, i = out[:n0], kind[:n0], i0
+	}
+	if grow && len(out) > 0 {
+		for n := 0; n < len(pos); n++ {
+			p, ok := get(i)
+			if !ok || !p.literal {
+				break
+			}
+			out = append(out, p.r)
+			kind = append(kind, maskLiteral)
+			i++
+		}
+	}
+	return out, kind
+}
+
+// Format returns the text formatted according to the mask. Invalid characters are removed.
+// If grow is true, literals following the last character are appended.
+func (m InputMask) Format(s string, grow bool) string {
+	out, _ := m.apply(s, grow)
+	return string(out)
+}
+
+// Strip returns the text with the literals given by the mask removed. Literals ending
+// a group early are kept, so that Format(Strip(s)) gives the formatted text back.
+// With MaskIPv4, "192.168.100.001" is stripped to "192168100001", and "10.0.0.1" is kept as is.
+func (m InputMask) Strip(s string) string {
+	out, kind := m.apply(s, false)
+	var b strings.Builder
+	for i, r := range out {
+		if kind[i] != maskLiteral {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Placeholder returns a text showing the mask, with all input positions replaced by '_'
+func (m InputMask) Placeholder() string {
+	pos, _ := m.parse()
+	var b strings.Builder
+	for _, p := range pos {
+		if p.literal {
+			b.WriteRune(p.r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// applyMask is called when the editor text has changed. It will reformat the text
+// according to the input mask, and keep the caret at the same position in the text.
+func (e *EditDef) applyMask() {
+	if e.inputMask == "" {
+		return
+	}
+	old := e.Text()
+	n := utf8.RuneCountInString(old)
+	start, end := e.Selection()
+	s := e.inputMask.Format(old, n > e.maskLen)
+	e.maskLen = utf8.RuneCountInString(s)
+	if s == old {
+		return
+	}
+	e.SetText(s)
+	if start >= n && end >= n {
+		e.SetCaret(e.maskLen, e.maskLen)
+	} else {
+		e.SetCaret(Min(start, e.maskLen), Min(end, e.maskLen))
+	}
+}
+
+// Masked is an option setting an input mask for the Edit. See InputMask for the syntax.
+func Masked(m InputMask) EditOption {
+	return func(e *EditDef) {
+		e.inputMask = m
+		if e.hint == "" {
+			e.hint = m.Placeholder()
+		}
+	}
+}
+
+// RawValue is an option used together with Masked. The variable bound to the
+// edit will then receive the value without the literals from the mask, as given by
+// InputMask.Strip. Literals typed to end a group early are kept in the value.
+func RawValue() EditOption {
+	return func(e *EditDef) {
+		e.rawValue = true
+	}
+}
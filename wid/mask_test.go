@@ -0,0 +1,35 @@
+package wid
+
+import (
+	"testing"
+)
+
+func TestMaskRoundTrip(t *testing.T) {
+	cases := []struct {
+		m    InputMask
+		text string
+		raw  string
+	}{
+		{MaskDate, "24.12.2024", "24122024"},
+		{MaskDate, "1.2.2024", "1.2.2024"},
+		{MaskDate, "24.1", "241"},
+		{MaskTime, "12:30", "1230"},
+		{MaskTime, "9:05", "9:05"},
+		{MaskIPv4, "192.168.100.001", "192168100001"},
+		{MaskIPv4, "10.0.0.1", "10.0.0.1"},
+		{MaskIPv4, "192.168.0.1", "1921680.1"},
+		{MaskMAC, "AA:BB:CC:DD:EE:FF", "AABBCCDDEEFF"},
+		{MaskMAC, "A:BB:C:DD:EE:FF", "A:BBC:DDEEFF"},
+		{MaskHexBytes, "0A 1B 2C", "0A1B2C"},
+		{MaskHexBytes, "A 1B C", "A 1BC"},
+	}
+	for _, c := range cases {
+		raw := c.m.Strip(c.text)
+		if raw != c.raw {
+			t.Errorf("%q.Strip(%q)=%q, want %q", c.m, c.text, raw, c.raw)
+		}
+		if s := c.m.Format(raw, false); s != c.text {
+			t.Errorf("%q.Format(%q)=%q, want %q", c.m, raw, s, c.text)
+		}
+	}
+}
@@ -0,0 +1,710 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// MenuDef is a top level menu in a menu bar, a submenu or a menu item.
+type MenuDef struct {
+	Base
+	text string
+	// mnemonic is the byte index of the underlined letter in text, or -1
+	mnemonic  int
+	accel     string
+	items     []*MenuDef
+	separator bool
+	// checked is used by items with a check mark
+	checked func() bool
+	click   gesture.Click
+}
+
+// MenuBarDef is a row of menus at the top of a window.
+type MenuBarDef struct {
+	Base
+	menus []*MenuDef
+	// open is the path of open menus. open[0] is the index of the top level menu,
+	// and open[k] is the index of the submenu opened from the menu at level k-1.
+	open []int
+	// hl is the index of the highlighted item in the innermost open menu, or -1
+	hl        int
+	hovered   *MenuDef
+	scope     ScopeDef
+	focused   bool
+	wantFocus bool
+	tops      []image.Rectangle
+	scrim     int
+}
+
+// MenuOption is options specific to menu items
+type MenuOption func(*MenuDef)
+
+// Accel is an option giving a menu item a keyboard accelerator like "Ctrl+S" or "F5".
+// The accelerator is shown to the right of the item text, and calls the Do() function
+// of the item when it is pressed, regardless of which widget has focus.
+func Accel(s string) MenuOption {
+	return func(m *MenuDef) {
+		m.accel = s
+	}
+}
+
+func (o MenuOption) apply(cfg interface{}) {
+	o(cfg.(*MenuDef))
+}
+
+func newMenu(th *Theme, label string) *MenuDef {
+	m := &MenuDef{
+		Base: Base{
+			th:        th,
+			role:      SurfaceContainer,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		mnemonic: -1,
+	}
+	// An & marks the mnemonic letter, and && is a literal &
+	var b strings.Builder
+	for i := 0; i < len(label); i++ {
+		if label[i] == '&' && i+1 < len(label) {
+			i++
+			if label[i] != '&' && m.mnemonic < 0 {
+				m.mnemonic = b.Len()
+			}
+		}
+		b.WriteByte(label[i])
+	}
+	m.text = b.String()
+	return m
+}
+
+// Menu returns a menu with the given items. It is used both for the top level
+// menus in a MenuBar and for submenus. An & in the label marks the mnemonic letter,
+// so "&File" is opened with Alt+F, and an open menu selects "&Save" when S is pressed.
+func Menu(th *Theme, label string, items ...*MenuDef) *MenuDef {
+	m := newMenu(th, label)
+	m.items = items
+	return m
+}
+
+// MenuItem returns an item in a menu. The Do() option gives the function called when
+// the item is selected, and En() disables it. Accel() adds a keyboard accelerator.
+func MenuItem(th *Theme, label string, options ...Option) *MenuDef {
+	m := newMenu(th, label)
+	for _, option := range options {
+		option.apply(m)
+	}
+	return m
+}
+
+// MenuSeparator returns a line between groups of menu items
+func MenuSeparator(th *Theme) *MenuDef {
+	m := newMenu(th, "")
+	m.separator = true
+	return m
+}
+
+// letter returns the key name of the mnemonic letter, or "" if there is none
+func (m *MenuDef) letter() key.Name {
+	if m.mnemonic < 0 {
+		return ""
+	}
+	r, _ := utf8.DecodeRuneInString(m.text[m.mnemonic:])
+	return key.Name(strings.ToUpper(string(r)))
+}
+
+// enabled is false when the disabler of the item is set
+func (m *MenuDef) enabled() bool {
+	if m.disabler == nil {
+		return true
+	}
+	GuiLock.RLock()
+	defer GuiLock.RUnlock()
+	return !*m.disabler
+}
+
+// selectable is true for items that can be highlighted by the keyboard
+func (m *MenuDef) selectable() bool {
+	return !m.separator && m.enabled()
+}
+
+// lastBar is the menu bar made last. Its shortcuts are removed when a new menu bar is made.
+var lastBar *MenuBarDef
+
+// MenuBar returns a row of menus. The accelerators of the menu items, and Alt with the
+// mnemonic of each top level menu, are registered as shortcuts, so they work when other widgets
+// have focus. They are active while the menu bar is shown, and not when a dialog is open.
+// When a menu is open, the arrow keys move between items and menus, Enter selects and Escape closes.
+// A window has one menu bar, and making a new one, like when the form is rebuilt, removes the
+// shortcuts of the previous menu bar.
+func MenuBar(th *Theme, menus ...*MenuDef) layout.Widget {
+	b := &MenuBarDef{
+		Base: Base{
+			th:        th,
+			role:      SurfaceContainer,
+			padding:   layout.Inset{Left: 12, Right: 12, Top: 6, Bottom: 6},
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		menus: menus,
+		hl:    -1,
+		scope: ScopeDef{frame: -1},
+	}
+	if lastBar != nil {
+		lastBar.scope.RemoveShortcuts()
+	}
+	lastBar = b
+	for i, m := range menus {
+		i := i
+		if k := m.letter(); k != "" {
+			b.register("Alt+"+string(k), m.text, func() {
+				b.openTop(i, true)
+			})
+		}
+		b.addAccels(m, m.text)
+	}
+	return b.Layout
+}
+
+// register adds a shortcut in the scope of the menu bar. Errors are programming errors, so they panic.
+func (b *MenuBarDef) register(keys string, desc string, do func()) {
+	if _, err := Shortcut(keys, do, In(&b.scope), Desc(desc)); err != nil {
+		panic(err)
+	}
+}
+
+// addAccels registers the accelerators of all items in the menu and its submenus.
+// The description is the path of the item, like "File > Save".
+func (b *MenuBarDef) addAccels(m *MenuDef, path string) {
+	for _, it := range m.items {
+		it := it
+		if it.accel != "" {
+			b.register(it.accel, path+" > "+it.text, func() {
+				if it.enabled() && it.onUserChange != nil {
+					it.onUserChange()
+				}
+			})
+		}
+		b.addAccels(it, path+" > "+it.text)
+	}
+}
+
+// menuAt returns the open menu at the given level
+func (b *MenuBarDef) menuAt(level int) *MenuDef {
+	m := b.menus[b.open[0]]
+	for _, j := range b.open[1 : level+1] {
+		m = m.items[j]
+	}
+	return m
+}
+
+// step returns the next selectable item in the direction d, starting at i
+func step(m *MenuDef, i, d int) int {
+	n := len(m.items)
+	for k := 0; k < n; k++ {
+		i = (i + d + n) % n
+		if m.items[i].selectable() {
+			return i
+		}
+	}
+	return -1
+}
+
+// openTop opens top level menu i. When opened from the keyboard, the first item is highlighted.
+func (b *MenuBarDef) openTop(i int, keyboard bool) {
+	if i < 0 || i >= len(b.menus) {
+		return
+	}
+	b.open = []int{i}
+	b.hl = -1
+	if keyboard {
+		b.hl = step(b.menus[i], -1, 1)
+	}
+	// The bar takes the focus while a menu is open, to get the arrow keys
+	b.wantFocus = !b.focused
+}
+
+// openSub opens the submenu at index j of the menu at the given level
+func (b *MenuBarDef) openSub(level, j int, keyboard bool) {
+	b.open = append(b.open[:level+1], j)
+	b.hl = -1
+	if keyboard {
+		b.hl = step(b.menuAt(level+1), -1, 1)
+	}
+}
+
+// closeAll closes all menus and gives the focus back
+func (b *MenuBarDef) closeAll(gtx C) {
+	b.open = nil
+	b.hl = -1
+	b.hovered = nil
+	if b.focused {
+		gtx.Execute(key.FocusCmd{Tag: nil})
+	}
+}
+
+// back closes the innermost submenu, highlighting the item it was opened from
+func (b *MenuBarDef) back() {
+	b.hl = b.open[len(b.open)-1]
+	b.open = b.open[:len(b.open)-1]
+}
+
+// activate opens a submenu or calls the function of an item
+func (b *MenuBarDef) activate(gtx C, level, j int, keyboard bool) {
+	it := b.menuAt(level).items[j]
+	if len(it.items) > 0 {
+		b.openSub(level, j, keyboard)
+		return
+	}
+	if !it.selectable() {
+		return
+	}
+	b.closeAll(gtx)
+	if it.onUserChange != nil {
+		it.onUserChange()
+		gtx.Execute(op.InvalidateCmd{})
+	}
+}
+
+// topAt returns the index of the top level menu at p, or -1
+func (b *MenuBarDef) topAt(p image.Point) int {
+	for i, r := range b.tops {
+		if p.In(r) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *MenuBarDef) handleEvents(gtx C) {
+	for i, m := range b.menus {
+		for {
+			e, ok := m.click.Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindPress {
+				if len(b.open) > 0 && b.open[0] == i {
+					b.closeAll(gtx)
+				} else {
+					b.openTop(i, false)
+				}
+			}
+		}
+	}
+	// Items in the open menus are clicked or hovered
+	for level := 0; level < len(b.open); level++ {
+		if b.handleItems(gtx, level) {
+			break
+		}
+	}
+	// The scrim catches presses outside the menus, and moves over the bar
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: &b.scrim, Kinds: pointer.Press | pointer.Move})
+		if !ok {
+			break
+		}
+		e, ok := ev.(pointer.Event)
+		if !ok || len(b.open) == 0 {
+			continue
+		}
+		i := b.topAt(e.Position.Round())
+		if e.Kind == pointer.Press && (i < 0 || i == b.open[0]) {
+			b.closeAll(gtx)
+		} else if i >= 0 && i != b.open[0] {
+			b.openTop(i, false)
+		}
+	}
+	for level := 0; level < len(b.open); level++ {
+		m := b.menuAt(level)
+		for {
+			// Presses in the popup outside of items are swallowed
+			if _, ok := gtx.Event(pointer.Filter{Target: m, Kinds: pointer.Press}); !ok {
+				break
+			}
+		}
+	}
+	b.handleKeys(gtx)
+}
+
+// handleItems handles the items of the menu at the given level.
+// It returns true when the open menus are changed by a click.
+func (b *MenuBarDef) handleItems(gtx C, level int) bool {
+	for j, it := range b.menuAt(level).items {
+		for {
+			e, ok := it.click.Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindClick {
+				b.activate(gtx, level, j, false)
+				return true
+			}
+		}
+		if it.click.Hovered() && it != b.hovered && !it.separator {
+			// Moving the mouse to an item closes deeper submenus and opens its own
+			b.hovered = it
+			if len(it.items) > 0 {
+				b.openSub(level, j, false)
+			} else {
+				b.open = b.open[:level+1]
+				b.hl = j
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// keyFilters returns the keys used by the bar, including the mnemonics of the innermost open menu
+func (b *MenuBarDef) keyFilters() []event.Filter {
+	filters := []event.Filter{
+		key.FocusFilter{Target: b},
+		key.Filter{Focus: b, Name: key.NameUpArrow},
+		key.Filter{Focus: b, Name: key.NameDownArrow},
+		key.Filter{Focus: b, Name: key.NameLeftArrow},
+		key.Filter{Focus: b, Name: key.NameRightArrow},
+		key.Filter{Focus: b, Name: key.NameReturn},
+		key.Filter{Focus: b, Name: key.NameEnter},
+		key.Filter{Focus: b, Name: key.NameSpace},
+		key.Filter{Focus: b, Name: key.NameEscape},
+	}
+	if len(b.open) > 0 {
+		for _, it := range b.menuAt(len(b.open) - 1).items {
+			if k := it.letter(); k != "" {
+				filters = append(filters, key.Filter{Focus: b, Name: k, Optional: key.ModShift})
+			}
+		}
+	}
+	return filters
+}
+
+func (b *MenuBarDef) handleKeys(gtx C) {
+	for {
+		// The filters change when another menu is opened, so they are found for each event
+		ev, ok := gtx.Event(b.keyFilters()...)
+		if !ok {
+			break
+		}
+		switch e := ev.(type) {
+		case key.FocusEvent:
+			b.focused = e.Focus
+			if !e.Focus && len(b.open) > 0 {
+				b.open, b.hl = nil, -1
+			}
+		case key.Event:
+			if e.State != key.Press || len(b.open) == 0 {
+				break
+			}
+			level := len(b.open) - 1
+			m := b.menuAt(level)
+			n := len(b.menus)
+			switch e.Name {
+			case key.NameDownArrow:
+				b.hl = step(m, b.hl, 1)
+			case key.NameUpArrow:
+				if b.hl < 0 {
+					b.hl = 0
+				}
+				b.hl = step(m, b.hl, -1)
+			case key.NameRightArrow:
+				if b.hl >= 0 && len(m.items[b.hl].items) > 0 {
+					b.openSub(level, b.hl, true)
+				} else {
+					b.openTop((b.open[0]+1)%n, true)
+				}
+			case key.NameLeftArrow:
+				if level > 0 {
+					b.back()
+				} else {
+					b.openTop((b.open[0]+n-1)%n, true)
+				}
+			case key.NameReturn, key.NameEnter, key.NameSpace:
+				if b.hl >= 0 {
+					b.activate(gtx, level, b.hl, true)
+				}
+			case key.NameEscape:
+				if level > 0 {
+					b.back()
+				} else {
+					b.closeAll(gtx)
+				}
+			default:
+				for j, it := range m.items {
+					if it.letter() == e.Name && it.selectable() {
+						b.activate(gtx, level, j, true)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// label draws a text with the mnemonic letter underlined
+func (b *MenuBarDef) label(gtx C, s string, mnemonic int, col op.CallOp) D {
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	textSize := b.th.TextSize * unit.Sp(b.FontScale)
+	lbl := widget.Label{MaxLines: 1}
+	dims := lbl.Layout(c, b.th.Shaper, *b.Font, textSize, s, col)
+	if mnemonic >= 0 && mnemonic < len(s) {
+		_, size := utf8.DecodeRuneInString(s[mnemonic:])
+		m := op.Record(gtx.Ops)
+		x0 := lbl.Layout(c, b.th.Shaper, *b.Font, textSize, s[:mnemonic], op.CallOp{}).Size.X
+		x1 := lbl.Layout(c, b.th.Shaper, *b.Font, textSize, s[:mnemonic+size], op.CallOp{}).Size.X
+		m.Stop()
+		if mnemonic == 0 {
+			x0 = 0
+		}
+		y := dims.Size.Y - dims.Baseline + Px(gtx, unit.Dp(2))
+		r := clip.Rect{Min: image.Pt(x0, y), Max: image.Pt(x1, y+Max(1, Px(gtx, unit.Dp(1))))}.Push(gtx.Ops)
+		col.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		r.Pop()
+	}
+	return dims
+}
+
+// textWidth returns the width of a text without drawing it
+func (b *MenuBarDef) textWidth(gtx C, s string) int {
+	if s == "" {
+		return 0
+	}
+	m := op.Record(gtx.Ops)
+	w := b.label(gtx, s, -1, op.CallOp{}).Size.X
+	m.Stop()
+	return w
+}
+
+// lineHeight returns the height of a text line
+func (b *MenuBarDef) lineHeight(gtx C) int {
+	m := op.Record(gtx.Ops)
+	h := b.label(gtx, "Mg", -1, op.CallOp{}).Size.Y
+	m.Stop()
+	return h
+}
+
+// color returns a recorded color operation, used for the labels
+func (b *MenuBarDef) color(gtx C, col color.NRGBA) op.CallOp {
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	return m.Stop()
+}
+
+// menuSize returns the size of a menu popup, and if it has an arrow or check column
+func (b *MenuBarDef) menuSize(gtx C, m *MenuDef) (image.Point, bool) {
+	padX, padY := Px(gtx, unit.Dp(12)), Px(gtx, unit.Dp(8))
+	itemH := b.lineHeight(gtx) + Px(gtx, unit.Dp(12))
+	textW, accelW, icon, h := 0, 0, false, 2*padY
+	for _, it := range m.items {
+		if it.separator {
+			h += Px(gtx, unit.Dp(9))
+			continue
+		}
+		h += itemH
+		textW = Max(textW, b.textWidth(gtx, it.text))
+		accelW = Max(accelW, b.textWidth(gtx, it.accel))
+		icon = icon || len(it.items) > 0 || it.checked != nil
+	}
+	w := textW + 2*padX
+	if accelW > 0 {
+		w += Px(gtx, unit.Dp(32)) + accelW
+	}
+	if icon {
+		w += Px(gtx, unit.Dp(20))
+	}
+	return image.Pt(Max(w, Px(gtx, unit.Dp(112))), h), icon
+}
+
+// layoutMenu draws the open menu at the given level, and its open submenu.
+// Submenus open to the right, or to the left when there is no space to the right of maxX.
+func (b *MenuBarDef) layoutMenu(gtx C, level int, pos image.Point, maxX int) {
+	m := b.menuAt(level)
+	padX, padY := Px(gtx, unit.Dp(12)), Px(gtx, unit.Dp(8))
+	lineH := b.lineHeight(gtx)
+	itemH := lineH + Px(gtx, unit.Dp(12))
+	sepH := Px(gtx, unit.Dp(9))
+	arrow := Px(gtx, unit.Dp(20))
+	size, icon := b.menuSize(gtx, m)
+	w, h := size.X, size.Y
+
+	o := op.Offset(pos).Push(gtx.Ops)
+	rect := image.Rect(0, 0, w, h)
+	rr := Px(gtx, unit.Dp(4))
+	DrawShadow(gtx, rect, rr, Px(gtx, unit.Dp(6)))
+	cl := clip.UniformRRect(rect, rr).Push(gtx.Ops)
+	paint.Fill(gtx.Ops, b.th.Bg[SurfaceContainer])
+	event.Op(gtx.Ops, m)
+	pointer.CursorDefault.Add(gtx.Ops)
+	fg := b.th.Fg[SurfaceContainer]
+	muted := b.th.Fg[SurfaceVariant]
+	y, sub, subY := padY, -1, 0
+	for j, it := range m.items {
+		if it.separator {
+			dh := Max(1, Px(gtx, unit.Dp(1)))
+			paint.FillShape(gtx.Ops, b.th.Bg[OutlineVariant], clip.Rect{Min: image.Pt(0, y+(sepH-dh)/2), Max: image.Pt(w, y+(sepH+dh)/2)}.Op())
+			y += sepH
+			continue
+		}
+		r := image.Rect(0, y, w, y+itemH)
+		isOpen := level+1 < len(b.open) && b.open[level+1] == j
+		if isOpen {
+			sub, subY = j, y
+		}
+		col, accCol := fg, muted
+		if !it.enabled() {
+			col, accCol = Disabled(fg), Disabled(muted)
+		} else if isOpen || (level == len(b.open)-1 && b.hl == j) {
+			paint.FillShape(gtx.Ops, MulAlpha(fg, 20), clip.Rect(r).Op())
+		}
+		ty := y + (itemH-lineH)/2
+		t := op.Offset(image.Pt(padX, ty)).Push(gtx.Ops)
+		b.label(gtx, it.text, it.mnemonic, b.color(gtx, col))
+		t.Pop()
+		if it.accel != "" {
+			x := w - padX - b.textWidth(gtx, it.accel)
+			if icon {
+				x -= arrow
+			}
+			t := op.Offset(image.Pt(x, ty)).Push(gtx.Ops)
+			b.label(gtx, it.accel, -1, b.color(gtx, accCol))
+			t.Pop()
+		}
+		// Submenus have an arrow, and checked items a check mark
+		var ic *Icon
+		if len(it.items) > 0 {
+			ic = nextIcon
+		} else if it.checked != nil && it.checked() {
+			ic = checkIcon
+		}
+		if ic != nil {
+			t := op.Offset(image.Pt(w-padX/2-arrow, y+(itemH-arrow)/2)).Push(gtx.Ops)
+			c := gtx
+			c.Constraints = layout.Exact(image.Pt(arrow, arrow))
+			ic.Layout(c, col)
+			t.Pop()
+		}
+		a := clip.Rect(r).Push(gtx.Ops)
+		it.click.Add(gtx.Ops)
+		a.Pop()
+		y += itemH
+	}
+	cl.Pop()
+	o.Pop()
+	if sub >= 0 {
+		// Submenus overlap the menu slightly
+		ov := Px(gtx, unit.Dp(4))
+		sw, _ := b.menuSize(gtx, m.items[sub])
+		p := image.Pt(pos.X+w-ov, pos.Y+subY-padY)
+		if p.X+sw.X > maxX {
+			p.X = Max(0, pos.X-sw.X+ov)
+		}
+		b.layoutMenu(gtx, level+1, p, maxX)
+	}
+}
+
+// layoutPopups draws the open menus on top of the other widgets, with the top level menu at pos.
+// The scrim below them covers the whole window, and catches presses outside the menus.
+func (b *MenuBarDef) layoutPopups(gtx C, pos image.Point, maxX int) {
+	if len(b.open) == 0 {
+		return
+	}
+	macro := op.Record(gtx.Ops)
+	s := clip.Rect(image.Rect(-inf, -inf, inf, inf)).Push(gtx.Ops)
+	event.Op(gtx.Ops, &b.scrim)
+	s.Pop()
+	size, _ := b.menuSize(gtx, b.menuAt(0))
+	pos.X = Max(0, Min(pos.X, maxX-size.X))
+	b.layoutMenu(gtx, 0, pos, maxX)
+	op.Defer(gtx.Ops, macro.Stop())
+}
+
+// popup is used instead of Layout when the menu is opened by a button at r, like the more button
+// of a toolbar. The menu is aligned with the button, within maxX.
+func (b *MenuBarDef) popup(gtx C, r image.Rectangle, maxX int) {
+	b.handleEvents(gtx)
+	if b.wantFocus {
+		gtx.Execute(key.FocusCmd{Tag: b})
+		b.wantFocus = false
+	}
+	b.tops = append(b.tops[:0], r)
+	// The tag for the keyboard focus must not take the pointer events of the button
+	cl := clip.Rect(r).Push(gtx.Ops)
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	event.Op(gtx.Ops, b)
+	pass.Pop()
+	cl.Pop()
+	b.layoutPopups(gtx, image.Pt(r.Min.X, r.Max.Y), maxX)
+}
+
+// Layout draws the menu bar, and the open menus on top of the other widgets
+func (b *MenuBarDef) Layout(gtx C) D {
+	// The shortcuts of the menus are active while the menu bar is shown and enabled
+	b.scope.Layout(gtx)
+	b.handleEvents(gtx)
+	if b.wantFocus {
+		gtx.Execute(key.FocusCmd{Tag: b})
+		b.wantFocus = false
+	}
+	pl, pr := Px(gtx, b.padding.Left), Px(gtx, b.padding.Right)
+	pt, pb := Px(gtx, b.padding.Top), Px(gtx, b.padding.Bottom)
+	lineH := b.lineHeight(gtx)
+	height := lineH + pt + pb
+	width := gtx.Constraints.Max.X
+	row := image.Rect(0, 0, width, height)
+	paint.FillShape(gtx.Ops, b.Bg(), clip.Rect(row).Op())
+	cl := clip.Rect(row).Push(gtx.Ops)
+	event.Op(gtx.Ops, b)
+	b.tops = b.tops[:0]
+	col := b.Fg()
+	if !gtx.Enabled() {
+		col = Disabled(col)
+	}
+	x := 0
+	for i, m := range b.menus {
+		r := image.Rect(x, 0, x+pl+b.textWidth(gtx, m.text)+pr, height)
+		b.tops = append(b.tops, r)
+		if len(b.open) > 0 && b.open[0] == i {
+			paint.FillShape(gtx.Ops, MulAlpha(col, 30), clip.Rect(r).Op())
+		} else if m.click.Hovered() {
+			paint.FillShape(gtx.Ops, MulAlpha(col, 20), clip.Rect(r).Op())
+		}
+		o := op.Offset(image.Pt(x+pl, pt)).Push(gtx.Ops)
+		b.label(gtx, m.text, m.mnemonic, b.color(gtx, col))
+		o.Pop()
+		a := clip.Rect(r).Push(gtx.Ops)
+		m.click.Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		a.Pop()
+		x = r.Max.X
+	}
+	cl.Pop()
+	// The menus are deferred so that they are drawn on top of the rest of the form
+	if len(b.open) > 0 {
+		b.layoutPopups(gtx, image.Pt(b.tops[b.open[0]].Min.X, height), width)
+	}
+	return D{Size: row.Max}
+}
+
+var checkIcon *Icon
+
+func init() {
+	checkIcon, _ = NewIcon(icons.NavigationCheck)
+}
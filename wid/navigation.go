@@ -0,0 +1,474 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// NavItem is a destination in a navigation rail or drawer. Page is the widget shown when
+// the item is selected. A badge with the number is shown when Badge points to a positive value.
+type NavItem struct {
+	Text  string
+	Icon  *Icon
+	Badge *int
+	Page  layout.Widget
+}
+
+// NavDef is a navigation rail or drawer, with the page of the selected item beside it.
+type NavDef struct {
+	Base
+	index  *int
+	items  []NavItem
+	rail   bool
+	modal  *bool
+	title  string
+	clicks []gesture.Click
+	// The selection pill moves from indFrom to indTo, and ind is its current y position
+	ind, indFrom, indTo float32
+	indStart            time.Time
+	// slide is the position of the modal drawer, from 0 (closed) to 1 (open)
+	slide      float32
+	slideStart time.Time
+	slideOpen  bool
+	focused    bool
+}
+
+// NavOption is options specific to navigation rails and drawers
+type NavOption func(*NavDef)
+
+// ModalDrawer is an option making a navigation drawer modal. It is shown on top of the
+// page when open is true, and closed when an item is selected, or by Escape or a click outside.
+func ModalDrawer(open *bool) NavOption {
+	return func(n *NavDef) {
+		n.modal = open
+	}
+}
+
+// NavTitle is an option giving a navigation drawer a headline above the items
+func NavTitle(s string) NavOption {
+	return func(n *NavDef) {
+		n.title = s
+	}
+}
+
+func (o NavOption) apply(cfg interface{}) {
+	o(cfg.(*NavDef))
+}
+
+func newNav(th *Theme, index *int, items []NavItem, rail bool, options []Option) *NavDef {
+	n := &NavDef{
+		Base: Base{
+			th:        th,
+			role:      Surface,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		index:  index,
+		items:  items,
+		rail:   rail,
+		clicks: make([]gesture.Click, len(items)),
+		ind:    -1,
+	}
+	if !rail {
+		n.role = SurfaceContainerLow
+	}
+	for _, option := range options {
+		option.apply(n)
+	}
+	return n
+}
+
+// NavigationRail returns a narrow column of destinations with icons and short labels, at the left
+// of the page of the selected item. The index of the selected item is read from and written to index.
+func NavigationRail(th *Theme, index *int, items []NavItem, options ...Option) layout.Widget {
+	return newNav(th, index, items, true, options).Layout
+}
+
+// NavigationDrawer returns a column of destinations with icons, labels and badges. The standard
+// drawer is always shown at the left of the page. With the ModalDrawer() option, it is shown on
+// top of the page while it is open.
+func NavigationDrawer(th *Theme, index *int, items []NavItem, options ...Option) layout.Widget {
+	return newNav(th, index, items, false, options).Layout
+}
+
+// setIndex selects item i, and calls the Do() function. The selection is navigation,
+// not form data, so it is not recorded in the undo history.
+func (n *NavDef) setIndex(i int) {
+	GuiLock.Lock()
+	old := *n.index
+	*n.index = i
+	GuiLock.Unlock()
+	if old != i && n.onUserChange != nil {
+		n.onUserChange()
+	}
+}
+
+// setOpen opens or closes the modal drawer
+func (n *NavDef) setOpen(open bool) {
+	GuiLock.Lock()
+	*n.modal = open
+	GuiLock.Unlock()
+}
+
+func (n *NavDef) handleEvents(gtx C, sel int, open bool) {
+	for i := range n.clicks {
+		for {
+			e, ok := n.clicks[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindClick {
+				n.setIndex(i)
+				if n.modal != nil {
+					n.setOpen(false)
+				}
+				gtx.Execute(key.FocusCmd{Tag: n})
+			}
+		}
+	}
+	for {
+		ev, ok := gtx.Event(
+			key.FocusFilter{Target: n},
+			key.Filter{Focus: n, Name: key.NameUpArrow},
+			key.Filter{Focus: n, Name: key.NameDownArrow},
+			key.Filter{Focus: n, Name: key.NameHome},
+			key.Filter{Focus: n, Name: key.NameEnd},
+			key.Filter{Focus: n, Name: key.NameEscape},
+			pointer.Filter{Target: &n.slide, Kinds: pointer.Press},
+		)
+		if !ok {
+			break
+		}
+		switch e := ev.(type) {
+		case key.FocusEvent:
+			n.focused = e.Focus
+		case key.Event:
+			if e.State != key.Press || len(n.items) == 0 {
+				break
+			}
+			switch e.Name {
+			case key.NameUpArrow:
+				n.setIndex(Max(0, sel-1))
+			case key.NameDownArrow:
+				n.setIndex(Min(len(n.items)-1, sel+1))
+			case key.NameHome:
+				n.setIndex(0)
+			case key.NameEnd:
+				n.setIndex(len(n.items) - 1)
+			case key.NameEscape:
+				if n.modal != nil && open {
+					n.setOpen(false)
+				}
+			}
+		case pointer.Event:
+			// A click on the scrim closes the modal drawer
+			if open {
+				n.setOpen(false)
+			}
+		}
+	}
+}
+
+// moveIndicator starts the animation of the selection pill when the selection is changed
+func (n *NavDef) moveIndicator(gtx C, y float32) {
+	if n.indTo != y || n.ind < 0 {
+		n.indFrom, n.indTo, n.indStart = n.ind, y, gtx.Now
+		if n.ind < 0 {
+			// No animation the first time
+			n.indFrom = y
+		}
+	}
+	p := float32(gtx.Now.Sub(n.indStart)) / float32(tabAnimation)
+	if p < 1 {
+		gtx.Execute(op.InvalidateCmd{})
+		p = 1 - (1-p)*(1-p)
+	} else {
+		p = 1
+	}
+	n.ind = n.indFrom + (n.indTo-n.indFrom)*p
+}
+
+// moveDrawer animates the modal drawer when it is opened or closed
+func (n *NavDef) moveDrawer(gtx C, open bool) {
+	if open != n.slideOpen {
+		n.slideOpen = open
+		// Start from the current position if the drawer is moving
+		d := time.Duration(n.slide * float32(tabAnimation))
+		if !open {
+			d = time.Duration((1 - n.slide) * float32(tabAnimation))
+		}
+		n.slideStart = gtx.Now.Add(-d)
+	}
+	p := Clamp(float32(gtx.Now.Sub(n.slideStart))/float32(tabAnimation), 0, 1)
+	if p < 1 {
+		gtx.Execute(op.InvalidateCmd{})
+	}
+	if open {
+		n.slide = p
+	} else {
+		n.slide = 1 - p
+	}
+}
+
+func (n *NavDef) label(gtx C, s string, scale float64, weight font.Weight, col op.CallOp) D {
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	f := *n.Font
+	f.Weight = weight
+	return widget.Label{MaxLines: 1}.Layout(c, n.th.Shaper, f, n.th.TextSize*unit.Sp(n.FontScale*scale), s, col)
+}
+
+func (n *NavDef) color(gtx C, col color.NRGBA) op.CallOp {
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	return m.Stop()
+}
+
+// badgeText is the text in a badge, or "" when there is no badge
+func badgeText(b *int) string {
+	if b == nil {
+		return ""
+	}
+	GuiLock.RLock()
+	v := *b
+	GuiLock.RUnlock()
+	if v <= 0 {
+		return ""
+	} else if v > 999 {
+		return "999+"
+	}
+	return strconv.Itoa(v)
+}
+
+// layoutRail draws the rail, and returns its width
+func (n *NavDef) layoutRail(gtx C, sel int) int {
+	width, height := Px(gtx, unit.Dp(80)), gtx.Constraints.Max.Y
+	pillW, pillH := Px(gtx, unit.Dp(56)), Px(gtx, unit.Dp(32))
+	icon := Px(gtx, unit.Dp(24))
+	top := Px(gtx, unit.Dp(12))
+	m := op.Record(gtx.Ops)
+	labelH := n.label(gtx, "Mg", 0.8, font.Medium, op.CallOp{}).Size.Y
+	m.Stop()
+	itemH := pillH + Px(gtx, unit.Dp(4)) + labelH + Px(gtx, unit.Dp(12))
+	paint.FillShape(gtx.Ops, n.Bg(), clip.Rect{Max: image.Pt(width, height)}.Op())
+	cl := clip.Rect{Max: image.Pt(width, height)}.Push(gtx.Ops)
+	event.Op(gtx.Ops, n)
+	if sel >= 0 && sel < len(n.items) {
+		n.moveIndicator(gtx, float32(top+sel*itemH))
+		y := int(n.ind)
+		col := n.th.Bg[SecondaryContainer]
+		if !gtx.Enabled() {
+			col = Disabled(col)
+		}
+		paint.FillShape(gtx.Ops, col, clip.UniformRRect(image.Rect((width-pillW)/2, y, (width+pillW)/2, y+pillH), pillH/2).Op(gtx.Ops))
+	}
+	for i, item := range n.items {
+		y := top + i*itemH
+		pill := image.Rect((width-pillW)/2, y, (width+pillW)/2, y+pillH)
+		fg, iconCol, weight := n.th.Fg[SurfaceVariant], n.th.Fg[SurfaceVariant], font.Medium
+		if i == sel {
+			fg, iconCol, weight = n.th.Fg[Surface], n.th.Fg[SecondaryContainer], font.Bold
+		}
+		if !gtx.Enabled() {
+			fg, iconCol = Disabled(fg), Disabled(iconCol)
+		}
+		if n.clicks[i].Pressed() {
+			paint.FillShape(gtx.Ops, MulAlpha(iconCol, 30), clip.UniformRRect(pill, pillH/2).Op(gtx.Ops))
+		} else if n.clicks[i].Hovered() {
+			paint.FillShape(gtx.Ops, MulAlpha(iconCol, 20), clip.UniformRRect(pill, pillH/2).Op(gtx.Ops))
+		}
+		if i == sel && n.focused {
+			paintBorder(gtx, pill.Inset(-Px(gtx, unit.Dp(2))), n.th.Bg[Primary], float32(Px(gtx, unit.Dp(2))), pillH/2)
+		}
+		ip := image.Pt((width-icon)/2, y+(pillH-icon)/2)
+		if item.Icon != nil {
+			o := op.Offset(ip).Push(gtx.Ops)
+			ic := gtx
+			ic.Constraints = layout.Exact(image.Pt(icon, icon))
+			item.Icon.Layout(ic, iconCol)
+			o.Pop()
+		}
+		if b := badgeText(item.Badge); b != "" {
+			// The badge is at the top right of the icon
+			m := op.Record(gtx.Ops)
+			d := n.label(gtx, b, 0.6, font.Medium, n.color(gtx, n.th.Fg[Error]))
+			call := m.Stop()
+			bh := d.Size.Y
+			bw := Max(bh, d.Size.X+bh/2)
+			br := image.Rect(ip.X+icon/2, ip.Y-bh/3, ip.X+icon/2+bw, ip.Y-bh/3+bh)
+			paint.FillShape(gtx.Ops, n.th.Bg[Error], clip.UniformRRect(br, bh/2).Op(gtx.Ops))
+			o := op.Offset(image.Pt(br.Min.X+(bw-d.Size.X)/2, br.Min.Y)).Push(gtx.Ops)
+			call.Add(gtx.Ops)
+			o.Pop()
+		}
+		m := op.Record(gtx.Ops)
+		d := n.label(gtx, item.Text, 0.8, weight, n.color(gtx, fg))
+		call := m.Stop()
+		o := op.Offset(image.Pt((width-d.Size.X)/2, y+pillH+Px(gtx, unit.Dp(4)))).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		o.Pop()
+		a := clip.Rect(image.Rect(0, y, width, y+itemH)).Push(gtx.Ops)
+		n.clicks[i].Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		a.Pop()
+	}
+	cl.Pop()
+	return width
+}
+
+// layoutDrawer draws the drawer with the given width
+func (n *NavDef) layoutDrawer(gtx C, sel int, width int) {
+	height := gtx.Constraints.Max.Y
+	margin := Px(gtx, unit.Dp(12))
+	itemH := Px(gtx, unit.Dp(56))
+	icon := Px(gtx, unit.Dp(24))
+	rect := image.Rect(0, 0, width, height)
+	if n.modal != nil {
+		// The modal drawer has rounded corners at the right side
+		rr := Px(gtx, unit.Dp(16))
+		DrawShadow(gtx, rect, rr, Px(gtx, unit.Dp(6)))
+		paint.FillShape(gtx.Ops, n.Bg(), clip.RRect{Rect: rect, NE: rr, SE: rr}.Op(gtx.Ops))
+	} else {
+		paint.FillShape(gtx.Ops, n.Bg(), clip.Rect(rect).Op())
+	}
+	cl := clip.Rect(rect).Push(gtx.Ops)
+	event.Op(gtx.Ops, n)
+	pointer.CursorDefault.Add(gtx.Ops)
+	top := margin
+	if n.title != "" {
+		m := op.Record(gtx.Ops)
+		d := n.label(gtx, n.title, 1.0, font.Medium, n.color(gtx, n.th.Fg[SurfaceVariant]))
+		call := m.Stop()
+		o := op.Offset(image.Pt(2*margin+margin/3, top+(itemH-d.Size.Y)/2)).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		o.Pop()
+		top += itemH
+	}
+	if sel >= 0 && sel < len(n.items) {
+		n.moveIndicator(gtx, float32(top+sel*itemH))
+		y := int(n.ind)
+		col := n.th.Bg[SecondaryContainer]
+		if !gtx.Enabled() {
+			col = Disabled(col)
+		}
+		paint.FillShape(gtx.Ops, col, clip.UniformRRect(image.Rect(margin, y, width-margin, y+itemH), itemH/2).Op(gtx.Ops))
+	}
+	for i, item := range n.items {
+		y := top + i*itemH
+		pill := image.Rect(margin, y, width-margin, y+itemH)
+		fg, weight := n.th.Fg[SurfaceVariant], font.Medium
+		if i == sel {
+			fg, weight = n.th.Fg[SecondaryContainer], font.Bold
+		}
+		if !gtx.Enabled() {
+			fg = Disabled(fg)
+		}
+		if n.clicks[i].Pressed() {
+			paint.FillShape(gtx.Ops, MulAlpha(fg, 30), clip.UniformRRect(pill, itemH/2).Op(gtx.Ops))
+		} else if n.clicks[i].Hovered() {
+			paint.FillShape(gtx.Ops, MulAlpha(fg, 20), clip.UniformRRect(pill, itemH/2).Op(gtx.Ops))
+		}
+		if i == sel && n.focused {
+			paintBorder(gtx, pill, n.th.Bg[Primary], float32(Px(gtx, unit.Dp(2))), itemH/2)
+		}
+		x := margin + Px(gtx, unit.Dp(16))
+		if item.Icon != nil {
+			o := op.Offset(image.Pt(x, y+(itemH-icon)/2)).Push(gtx.Ops)
+			ic := gtx
+			ic.Constraints = layout.Exact(image.Pt(icon, icon))
+			item.Icon.Layout(ic, fg)
+			o.Pop()
+			x += icon + Px(gtx, unit.Dp(12))
+		}
+		m := op.Record(gtx.Ops)
+		d := n.label(gtx, item.Text, 1.0, weight, n.color(gtx, fg))
+		call := m.Stop()
+		o := op.Offset(image.Pt(x, y+(itemH-d.Size.Y)/2)).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		o.Pop()
+		if b := badgeText(item.Badge); b != "" {
+			m := op.Record(gtx.Ops)
+			d := n.label(gtx, b, 0.9, font.Medium, n.color(gtx, fg))
+			call := m.Stop()
+			o := op.Offset(image.Pt(width-margin-Px(gtx, unit.Dp(24))-d.Size.X, y+(itemH-d.Size.Y)/2)).Push(gtx.Ops)
+			call.Add(gtx.Ops)
+			o.Pop()
+		}
+		a := clip.Rect(pill).Push(gtx.Ops)
+		n.clicks[i].Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		a.Pop()
+	}
+	cl.Pop()
+}
+
+// Layout draws the navigation, and the page of the selected item beside it or below the modal drawer
+func (n *NavDef) Layout(gtx C) D {
+	GuiLock.RLock()
+	sel := *n.index
+	open := n.modal != nil && *n.modal
+	GuiLock.RUnlock()
+	n.handleEvents(gtx, sel, open)
+	GuiLock.RLock()
+	sel = *n.index
+	if n.modal != nil {
+		open = *n.modal
+	}
+	GuiLock.RUnlock()
+
+	size := gtx.Constraints.Max
+	drawerW := Min(Px(gtx, unit.Dp(360)), size.X*4/5)
+	x := 0
+	if n.rail {
+		x = n.layoutRail(gtx, sel)
+	} else if n.modal == nil {
+		n.layoutDrawer(gtx, sel, drawerW)
+		x = drawerW
+	}
+	if sel >= 0 && sel < len(n.items) && n.items[sel].Page != nil {
+		c := gtx
+		c.Constraints = layout.Exact(image.Pt(Max(0, size.X-x), size.Y))
+		if open {
+			// The page does not get events while the modal drawer is open
+			c = c.Disabled()
+		}
+		o := op.Offset(image.Pt(x, 0)).Push(gtx.Ops)
+		cl := clip.Rect{Max: c.Constraints.Max}.Push(gtx.Ops)
+		n.items[sel].Page(c)
+		cl.Pop()
+		o.Pop()
+	}
+	if n.modal != nil {
+		n.moveDrawer(gtx, open)
+		if n.slide > 0 {
+			// The scrim shades the page, and catches clicks outside the drawer
+			cl := clip.Rect{Max: size}.Push(gtx.Ops)
+			paint.Fill(gtx.Ops, WithAlpha(Black, uint8(n.slide*82)))
+			event.Op(gtx.Ops, &n.slide)
+			cl.Pop()
+			o := op.Offset(image.Pt(int(float32(drawerW)*(n.slide-1)), 0)).Push(gtx.Ops)
+			n.layoutDrawer(gtx, sel, drawerW)
+			o.Pop()
+		}
+		if open && !n.focused {
+			gtx.Execute(key.FocusCmd{Tag: n})
+		}
+	}
+	return D{Size: size}
+}
@@ -25,6 +25,7 @@ type BaseIf interface {
 	setBorder(b unit.Dp)
 	setDp(dp *int)
 	setAlignment(x text.Alignment)
+	setUntracked()
 }
 
 // BaseOption is a type for optional parameters when creating widgets
@@ -96,6 +97,10 @@ func (wid *Base) setAlignment(x text.Alignment) {
 	wid.Alignment = x
 }
 
+func (wid *Base) setUntracked() {
+	wid.untracked = true
+}
+
 func (wid *Base) setBorder(w unit.Dp) {
 	wid.borderWidth = w
 }
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// layoutPopup draws the widget on a raised surface at the given position.
+// Like the dropdown list, it is deferred so that it is drawn on top of other widgets.
+// The tag is used to stop pointer events from reaching the widgets below the popup.
+func layoutPopup(gtx C, th *Theme, tag event.Tag, pos image.Point, w Wid) D {
+	macro := op.Record(gtx.Ops)
+	o := op.Offset(pos).Push(gtx.Ops)
+	c := gtx
+	c.Constraints.Min = image.Point{}
+	m := op.Record(gtx.Ops)
+	dims := w(c)
+	call := m.Stop()
+	rect := image.Rectangle{Max: dims.Size}
+	rr := Px(gtx, th.BorderCornerRadius)
+	DrawShadow(gtx, rect, rr, Px(gtx, unit.Dp(6)))
+	cl := clip.UniformRRect(rect, rr).Push(gtx.Ops)
+	paint.Fill(gtx.Ops, th.Bg[SurfaceContainerHigh])
+	event.Op(gtx.Ops, tag)
+	pointer.CursorDefault.Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	cl.Pop()
+	o.Pop()
+	op.Defer(gtx.Ops, macro.Stop())
+	for {
+		// Pointer events are just swallowed
+		_, ok := gtx.Event(pointer.Filter{Target: tag, Kinds: pointer.Press | pointer.Release})
+		if !ok {
+			break
+		}
+	}
+	return dims
+}
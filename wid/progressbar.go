@@ -49,6 +49,10 @@ func ScaleInset(gtx C, ins layout.Inset) (pt, pb, pl, pr int) {
 func (p *ProgressBarDef) Layout(gtx C) D {
 	pt, pb, pl, pr := ScaleInset(gtx, p.padding)
 	progressBarWidth := gtx.Constraints.Min.X - pl - pr
+	// A width given by W() is used when the constraints allow any width, like in a status bar
+	if w := Px(gtx, p.width); w > gtx.Constraints.Min.X {
+		progressBarWidth = Min(w, gtx.Constraints.Max.X) - pl - pr
+	}
 	GuiLock.RLock()
 	value := *p.Progress
 	GuiLock.RUnlock()
@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"unicode"
+
+	"gioui.org/font"
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// monoTypeface is used for spans with Mono()
+const monoTypeface = font.Typeface("Go Mono, monospace")
+
+// Span is a piece of text with its own style, used by RichText.
+// Spans are made by Txt() or Link(), and styled by chaining the methods below, like
+// wid.Txt("Warning").Bold().Color(wid.Error)
+type Span struct {
+	text      string
+	bold      bool
+	italic    bool
+	mono      bool
+	scale     float32
+	color     *UIRole
+	highlight *UIRole
+	onClick   func()
+}
+
+// Txt returns a span with plain text
+func Txt(s string) Span {
+	return Span{text: s, scale: 1.0}
+}
+
+// Link returns a span that calls onClick when it is clicked.
+func Link(s string, onClick func()) Span {
+	return Span{text: s, scale: 1.0, onClick: onClick}
+}
+
+// Bold returns the span with bold text
+func (s Span) Bold() Span {
+	s.bold = true
+	return s
+}
+
+// Italic returns the span with italic text
+func (s Span) Italic() Span {
+	s.italic = true
+	return s
+}
+
+// Mono returns the span with a monospace font, typically used for code.
+func (s Span) Mono() Span {
+	s.mono = true
+	return s
+}
+
+// Scale returns the span with the text size scaled by f
+func (s Span) Scale(f float32) Span {
+	s.scale = f
+	return s
+}
+
+// Color returns the span with the text drawn in the main color of the role, like Primary or Error.
+func (s Span) Color(r UIRole) Span {
+	s.color = &r
+	return s
+}
+
+// Highlight returns the span with the background color of the role behind the text.
+func (s Span) Highlight(r UIRole) Span {
+	s.highlight = &r
+	return s
+}
+
+// RichTextDef is a text with spans of different styles. The text is wrapped at word boundaries.
+type RichTextDef struct {
+	Base
+	spans  interface{}
+	clicks []gesture.Click
+}
+
+// richWord is a shaped word or space, ready to be drawn.
+type richWord struct {
+	call     op.CallOp
+	size     image.Point
+	baseline int
+	span     int
+	space    bool
+}
+
+// RichText returns a widget showing a list of spans. The spans can be given as a pointer
+// to a slice, so that the text can be changed by the program.
+func RichText[V []Span | *[]Span](th *Theme, spans V, options ...Option) layout.Widget {
+	r := &RichTextDef{
+		Base: Base{
+			th:        th,
+			role:      Surface,
+			padding:   th.DefaultPadding,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+			Alignment: text.Start,
+		},
+		spans: spans,
+	}
+	for _, option := range options {
+		option.apply(r)
+	}
+	return r.Layout
+}
+
+// splitWords splits s into words, runs of spaces and line breaks.
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	runes := []rune(s)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '\n' || runes[i-1] == '\n' ||
+			unicode.IsSpace(runes[i]) != unicode.IsSpace(runes[i-1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return words
+}
+
+// textColor returns the color of the text in a span
+func (r *RichTextDef) textColor(gtx C, s *Span) op.CallOp {
+	col := r.Fg()
+	if s.color != nil {
+		col = r.th.Bg[*s.color]
+	} else if s.highlight != nil {
+		col = r.th.Fg[*s.highlight]
+	} else if s.onClick != nil {
+		col = r.th.Bg[Primary]
+	}
+	if !gtx.Enabled() {
+		col = Disabled(col)
+	}
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	return m.Stop()
+}
+
+// shapeWord records the drawing of a word in the style of the span
+func (r *RichTextDef) shapeWord(gtx C, s *Span, i int, w string) richWord {
+	f := *r.Font
+	if s.bold {
+		f.Weight = font.Bold
+	}
+	if s.italic {
+		f.Style = font.Italic
+	}
+	if s.mono {
+		f.Typeface = monoTypeface
+	}
+	size := r.th.TextSize * unit.Sp(r.FontScale) * unit.Sp(s.scale)
+	m := op.Record(gtx.Ops)
+	colorCall := r.textColor(gtx, s)
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	dims := widget.Label{MaxLines: 1}.Layout(c, r.th.Shaper, f, size, w, colorCall)
+	return richWord{call: m.Stop(), size: dims.Size, baseline: dims.Baseline, span: i, space: unicode.IsSpace([]rune(w)[0])}
+}
+
+// Layout handles link clicks and draws the text
+func (r *RichTextDef) Layout(gtx C) D {
+	pt, pb, pl, pr := ScaleInset(gtx, r.padding)
+	GuiLock.RLock()
+	var spans []Span
+	if s, ok := r.spans.(*[]Span); ok {
+		spans = *s
+	} else {
+		spans = r.spans.([]Span)
+	}
+	GuiLock.RUnlock()
+	if len(r.clicks) < len(spans) {
+		r.clicks = make([]gesture.Click, len(spans))
+	}
+	for i := range spans {
+		for spans[i].onClick != nil {
+			e, ok := r.clicks[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindClick && gtx.Enabled() {
+				spans[i].onClick()
+			}
+		}
+	}
+
+	// Place the words on lines, breaking lines when the next word does not fit
+	maxW := gtx.Constraints.Max.X - pl - pr
+	var lines [][]richWord
+	var line []richWord
+	x := 0
+	for i := range spans {
+		for _, w := range splitWords(spans[i].text) {
+			if w == "\n" {
+				lines = append(lines, line)
+				line, x = nil, 0
+				continue
+			}
+			word := r.shapeWord(gtx, &spans[i], i, w)
+			if word.space && x == 0 && len(lines) > 0 && len(line) == 0 {
+				// Spaces are dropped at the start of a wrapped line
+				continue
+			}
+			if !word.space && x > 0 && x+word.size.X > maxW {
+				lines = append(lines, line)
+				line, x = nil, 0
+			}
+			line = append(line, word)
+			x += word.size.X
+		}
+	}
+	lines = append(lines, line)
+
+	// Empty lines get the height of the default font
+	empty := r.shapeWord(gtx, &Span{scale: 1.0}, 0, "Mg")
+	width := 0
+	y := pt
+	// The background is filled when the size is known
+	bgMacro := op.Record(gtx.Ops)
+	for _, line := range lines {
+		ascent, descent, lineW := empty.size.Y-empty.baseline, empty.baseline, 0
+		if len(line) > 0 {
+			ascent, descent = 0, 0
+		}
+		for _, w := range line {
+			ascent = Max(ascent, w.size.Y-w.baseline)
+			descent = Max(descent, w.baseline)
+			lineW += w.size.X
+		}
+		x := pl
+		if r.Alignment == text.Middle {
+			x += (maxW - lineW) / 2
+		} else if r.Alignment == text.End {
+			x += maxW - lineW
+		}
+		for _, w := range line {
+			s := &spans[w.span]
+			pos := image.Pt(x, y+ascent-(w.size.Y-w.baseline))
+			rect := image.Rectangle{Min: pos, Max: pos.Add(w.size)}
+			if s.highlight != nil {
+				paint.FillShape(gtx.Ops, r.th.Bg[*s.highlight], clip.Rect(rect).Op())
+			}
+			o := op.Offset(pos).Push(gtx.Ops)
+			w.call.Add(gtx.Ops)
+			o.Pop()
+			if s.onClick != nil {
+				// Links are underlined, and have a pointer cursor
+				uy := y + ascent + Px(gtx, unit.Dp(1))
+				h := Max(1, Px(gtx, unit.Dp(1)))
+				paint.FillShape(gtx.Ops, r.th.Bg[Primary], clip.Rect{Min: image.Pt(rect.Min.X, uy), Max: image.Pt(rect.Max.X, uy+h)}.Op())
+				a := clip.Rect(rect).Push(gtx.Ops)
+				r.clicks[w.span].Add(gtx.Ops)
+				pointer.CursorPointer.Add(gtx.Ops)
+				a.Pop()
+			}
+			x += w.size.X
+		}
+		width = Max(width, lineW)
+		y += ascent + descent
+	}
+	call := bgMacro.Stop()
+	size := image.Pt(Max(gtx.Constraints.Min.X, width+pl+pr), y+pb)
+	if r.bgColor != nil {
+		paint.FillShape(gtx.Ops, *r.bgColor, clip.Rect{Max: size}.Op())
+	}
+	call.Add(gtx.Ops)
+	return D{Size: size}
+}
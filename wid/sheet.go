@@ -0,0 +1,343 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// SheetDef is a sheet with widgets at the bottom or the right edge of the window. Modal sheets
+// are shown on top of the form like dialogs, while a standard bottom sheet is shown below the page.
+type SheetDef struct {
+	Base
+	side    bool
+	modal   bool
+	heading string
+	// page is the widget below a standard bottom sheet
+	page layout.Widget
+	list layout.Widget
+	peek unit.Dp
+	// height is the height of a bottom sheet in pixels, 0 before the first frame
+	height int
+	// slide is how much of a modal sheet is shown, from 0 to 1. It slides out when closing is set.
+	slide      float32
+	slideStart time.Time
+	closing    bool
+	// offset is the distance a modal sheet is dragged down
+	offset    int
+	drag      gesture.Drag
+	dragStart float32
+	dragFrom  int
+	moved     bool
+	closeBtn  *ButtonDef
+}
+
+// SheetOption is options specific to sheets
+type SheetOption func(*SheetDef)
+
+const sheetAnimation = 300 * time.Millisecond
+
+// Peek is an option giving the height of a standard bottom sheet when it is collapsed.
+// The default is 120dp.
+func Peek(height unit.Dp) SheetOption {
+	return func(s *SheetDef) {
+		s.peek = height
+	}
+}
+
+func (o SheetOption) apply(cfg interface{}) {
+	o(cfg.(*SheetDef))
+}
+
+func newSheet(th *Theme, widgets []layout.Widget) *SheetDef {
+	return &SheetDef{
+		Base: Base{
+			th:        th,
+			role:      SurfaceContainerLow,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		list: List(th, Overlay, widgets...),
+		peek: 120,
+	}
+}
+
+// ModalBottomSheet returns a sheet with the widgets, sliding up from the bottom of the window.
+// Show it with Show or ShowModal. It is closed by Escape, a click outside the sheet, or by
+// dragging the handle down, and the result is then Cancelled. A long list of widgets is scrolled.
+func ModalBottomSheet(th *Theme, widgets ...layout.Widget) layout.Widget {
+	s := newSheet(th, widgets)
+	s.modal = true
+	return s.Layout
+}
+
+// SideSheet returns a sheet with the heading and the widgets, sliding in from the right edge
+// of the window. Show it with Show or ShowModal. It is closed by the close button,
+// Escape or a click outside the sheet, and the result is then Cancelled.
+func SideSheet(th *Theme, heading string, widgets ...layout.Widget) layout.Widget {
+	s := newSheet(th, widgets)
+	s.modal, s.side, s.heading = true, true, heading
+	s.closeBtn = toolButton(th, closeIcon, "Close", []Option{Do(s.dismiss)})
+	return s.Layout
+}
+
+// BottomSheet returns the page with a standard bottom sheet below it. The sheet has the height
+// given by the Peek() option, and is resized by dragging the handle. A click on the handle
+// expands or collapses it. The page is still used while the sheet is shown.
+func BottomSheet(th *Theme, page layout.Widget, sheet layout.Widget, options ...Option) layout.Widget {
+	s := newSheet(th, []layout.Widget{sheet})
+	s.page = page
+	for _, option := range options {
+		option.apply(s)
+	}
+	return s.Layout
+}
+
+// dismiss starts sliding the modal sheet out. It is closed when it is hidden.
+func (s *SheetDef) dismiss() {
+	if !s.closing {
+		s.closing, s.slideStart = true, time.Time{}
+		if invalidate != nil {
+			Invalidate()
+		}
+	}
+}
+
+// handleDrag moves the sheet while the handle is dragged. A modal sheet is closed when it is
+// dragged down more than a third of its height, and a click on the handle of a standard sheet
+// expands or collapses it.
+func (s *SheetDef) handleDrag(gtx C, height, peek int) {
+	for {
+		e, ok := s.drag.Update(gtx.Metric, gtx.Source, gesture.Vertical)
+		if !ok {
+			break
+		}
+		switch e.Kind {
+		case pointer.Press:
+			s.dragStart, s.moved = e.Position.Y, false
+			s.dragFrom = s.offset
+			if !s.modal {
+				s.dragFrom = s.height
+			}
+		case pointer.Drag:
+			dy := int(math.Round(float64(e.Position.Y - s.dragStart)))
+			s.moved = s.moved || dy*dy > Px(gtx, unit.Dp(4))*Px(gtx, unit.Dp(4))
+			if s.modal {
+				s.offset = Max(0, s.dragFrom+dy)
+			} else {
+				s.height = Clamp(s.dragFrom-dy, peek, height)
+			}
+		case pointer.Release, pointer.Cancel:
+			if s.modal && s.offset > height/3 {
+				s.dismiss()
+			} else if s.modal {
+				s.offset = 0
+			} else if !s.moved && s.height < height {
+				s.height = height
+			} else if !s.moved {
+				s.height = peek
+			}
+		}
+	}
+}
+
+// handleScrim closes the modal sheet when the scrim is clicked
+func (s *SheetDef) handleScrim(gtx C) {
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: &s.slide, Kinds: pointer.Press})
+		if !ok {
+			break
+		}
+		if _, ok := ev.(pointer.Event); ok {
+			s.dismiss()
+		}
+	}
+}
+
+// moveSheet animates the modal sheet when it is shown and closed
+func (s *SheetDef) moveSheet(gtx C) {
+	if current != nil && current.start.Equal(gtx.Now) {
+		// The sheet is shown again
+		s.closing, s.offset, s.slideStart = false, 0, gtx.Now
+	}
+	if s.slideStart.IsZero() {
+		// Slide out from the current position
+		s.slideStart = gtx.Now.Add(-time.Duration((1 - s.slide) * float32(sheetAnimation)))
+	}
+	p := Clamp(float32(gtx.Now.Sub(s.slideStart))/float32(sheetAnimation), 0, 1)
+	if p < 1 {
+		gtx.Execute(op.InvalidateCmd{})
+	}
+	if s.closing {
+		s.slide = 1 - p
+	} else {
+		s.slide = 1 - (1-p)*(1-p)
+	}
+}
+
+// drawHandle draws the drag handle centered at the top of the sheet
+func (s *SheetDef) drawHandle(gtx C, width int) {
+	w, h := Px(gtx, unit.Dp(32)), Px(gtx, unit.Dp(4))
+	r := image.Rect((width-w)/2, Px(gtx, unit.Dp(22)), (width+w)/2, Px(gtx, unit.Dp(22))+h)
+	paint.FillShape(gtx.Ops, MulAlpha(s.th.Fg[SurfaceVariant], 102), clip.UniformRRect(r, h/2).Op(gtx.Ops))
+}
+
+// layoutList records the widgets of the sheet, with the width and at most the height
+func (s *SheetDef) layoutList(gtx C, width, height int) (op.CallOp, D) {
+	c := gtx
+	c.Constraints = layout.Constraints{Min: image.Pt(width, 0), Max: image.Pt(width, Max(0, height))}
+	m := op.Record(gtx.Ops)
+	d := s.list(c)
+	return m.Stop(), d
+}
+
+// layoutBottom draws a bottom sheet with the top at y, and the handle dragged in the area
+func (s *SheetDef) layoutBottom(gtx C, x, y, width, height int, call op.CallOp) {
+	rr := Px(gtx, unit.Dp(28))
+	handleH := Px(gtx, unit.Dp(48))
+	r := image.Rect(x, y, x+width, y+height)
+	DrawShadow(gtx, r, rr, Px(gtx, unit.Dp(1)))
+	paint.FillShape(gtx.Ops, s.Bg(), clip.RRect{Rect: r, NW: rr, NE: rr}.Op(gtx.Ops))
+	// Clicks on the sheet do not reach the scrim or the page below it
+	a := clip.Rect(r).Push(gtx.Ops)
+	event.Op(gtx.Ops, s)
+	a.Pop()
+	// The drag area is not offset, so the drag positions do not move with the sheet
+	a = clip.Rect{Min: r.Min, Max: image.Pt(r.Max.X, y+handleH)}.Push(gtx.Ops)
+	s.drag.Add(gtx.Ops)
+	pointer.CursorGrab.Add(gtx.Ops)
+	a.Pop()
+	defer op.Offset(r.Min).Push(gtx.Ops).Pop()
+	defer clip.Rect{Max: r.Size()}.Push(gtx.Ops).Pop()
+	s.drawHandle(gtx, width)
+	o := op.Offset(image.Pt(0, handleH)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+}
+
+// layoutSide draws the side sheet at x, with the heading and close button above the widgets
+func (s *SheetDef) layoutSide(gtx C, x, width int) {
+	size := gtx.Constraints.Max
+	rr := Px(gtx, unit.Dp(16))
+	pad := Px(gtx, unit.Dp(24))
+	headH := Px(gtx, unit.Dp(72))
+	r := image.Rect(x, 0, x+width, size.Y)
+	DrawShadow(gtx, r, rr, Px(gtx, unit.Dp(1)))
+	paint.FillShape(gtx.Ops, s.Bg(), clip.RRect{Rect: r, NW: rr, SW: rr}.Op(gtx.Ops))
+	defer op.Offset(r.Min).Push(gtx.Ops).Pop()
+	defer clip.Rect{Max: r.Size()}.Push(gtx.Ops).Pop()
+	// Clicks on the sheet do not reach the scrim or the page below it
+	event.Op(gtx.Ops, s)
+
+	m := op.Record(gtx.Ops)
+	d := s.closeBtn.Layout(gtx)
+	call := m.Stop()
+	bx := width - Px(gtx, unit.Dp(12)) - d.Size.X
+	o := op.Offset(image.Pt(bx, (headH-d.Size.Y)/2)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(Max(0, bx-pad), headH)}
+	m = op.Record(gtx.Ops)
+	paint.ColorOp{Color: s.Fg()}.Add(gtx.Ops)
+	col := m.Stop()
+	f := *s.Font
+	f.Weight = font.Medium
+	m = op.Record(gtx.Ops)
+	d = widget.Label{MaxLines: 1}.Layout(c, s.th.Shaper, f, s.th.TextSize*unit.Sp(s.FontScale*1.4), s.heading, col)
+	call = m.Stop()
+	o = op.Offset(image.Pt(pad, (headH-d.Size.Y)/2)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+
+	call, _ = s.layoutList(gtx, width-2*pad, size.Y-headH-pad)
+	o = op.Offset(image.Pt(pad, headH)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+}
+
+// layoutStandard draws the page, and the standard bottom sheet below it
+func (s *SheetDef) layoutStandard(gtx C) D {
+	size := gtx.Constraints.Max
+	width := Min(size.X, Px(gtx, unit.Dp(640)))
+	pad := Px(gtx, unit.Dp(16))
+	handleH := Px(gtx, unit.Dp(48))
+	call, d := s.layoutList(gtx, width-2*pad, size.Y-Px(gtx, unit.Dp(72))-handleH-pad)
+	full := handleH + d.Size.Y + pad
+	peek := Min(full, Px(gtx, s.peek))
+	if s.height == 0 {
+		s.height = peek
+	}
+	s.handleDrag(gtx, full, peek)
+	s.height = Clamp(s.height, peek, full)
+
+	// The page is above the collapsed sheet
+	c := gtx
+	c.Constraints = layout.Exact(image.Pt(size.X, Max(0, size.Y-peek)))
+	cl := clip.Rect{Max: c.Constraints.Max}.Push(gtx.Ops)
+	s.page(c)
+	cl.Pop()
+
+	list := op.Record(gtx.Ops)
+	o := op.Offset(image.Pt(pad, 0)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+	s.layoutBottom(gtx, (size.X-width)/2, size.Y-s.height, width, s.height, list.Stop())
+	return D{Size: size}
+}
+
+// Layout draws the sheet. A modal sheet also draws the scrim shading the form below.
+func (s *SheetDef) Layout(gtx C) D {
+	if !s.modal {
+		return s.layoutStandard(gtx)
+	}
+	size := gtx.Constraints.Max
+	s.moveSheet(gtx)
+	if s.closing && s.slide == 0 {
+		// The sheet is closed, also when another dialog was shown while it slid out
+		closeDialog(current, Result{Button: Cancelled})
+		return D{Size: size}
+	}
+	if current != nil && gtx.Enabled() {
+		current.escape = s.dismiss
+	}
+	s.handleScrim(gtx)
+	// The scrim shades the form, and catches clicks outside the sheet
+	cl := clip.Rect{Max: size}.Push(gtx.Ops)
+	paint.Fill(gtx.Ops, WithAlpha(Black, uint8(s.slide*200)))
+	event.Op(gtx.Ops, &s.slide)
+	cl.Pop()
+
+	if s.side {
+		width := Min(Px(gtx, unit.Dp(360)), size.X*4/5)
+		s.layoutSide(gtx, size.X-int(s.slide*float32(width)), width)
+		return D{Size: size}
+	}
+	width := Min(size.X, Px(gtx, unit.Dp(640)))
+	pad := Px(gtx, unit.Dp(16))
+	handleH := Px(gtx, unit.Dp(48))
+	call, d := s.layoutList(gtx, width-2*pad, size.Y-Px(gtx, unit.Dp(72))-handleH-pad)
+	s.height = handleH + d.Size.Y + pad
+	s.handleDrag(gtx, s.height, s.height)
+	m := op.Record(gtx.Ops)
+	o := op.Offset(image.Pt(pad, 0)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	o.Pop()
+	y := size.Y - int(s.slide*float32(s.height)) + s.offset
+	s.layoutBottom(gtx, (size.X-width)/2, y, width, s.height, m.Stop())
+	return D{Size: size}
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/op"
+)
+
+// shortcut is a key combination that calls do when it is pressed
+type shortcut struct {
+	name   key.Name
+	mods   key.Modifiers
+	text   string
+	desc   string
+	scope  *ScopeDef
+	do     func()
+	active bool
+}
+
+// ScopeDef is a group of shortcuts that are only active while the scope is drawn.
+// It is used for shortcuts belonging to a form or a dialog.
+type ScopeDef struct {
+	w Wid
+	// frame is the last frame the scope was drawn, and order is the drawing
+	// order in that frame. Scopes drawn later, like dialogs, take precedence.
+	frame int
+	order int
+}
+
+// ShortcutInfo describes a registered shortcut, and is used to make help pages
+type ShortcutInfo struct {
+	Keys   string
+	Desc   string
+	Active bool
+}
+
+// ShortcutOption is options specific to shortcuts
+type ShortcutOption func(*shortcut)
+
+var (
+	shortcutMu    sync.Mutex
+	shortcuts     []*shortcut
+	shortcutFrame int
+	shortcutOrder int
+)
+
+// keyNames are the names used in shortcut texts that differ from the key names in gio
+var keyNames = map[string]key.Name{
+	"LEFT": key.NameLeftArrow, "RIGHT": key.NameRightArrow, "UP": key.NameUpArrow, "DOWN": key.NameDownArrow,
+	"ENTER": key.NameReturn, "RETURN": key.NameReturn, "ESC": key.NameEscape, "ESCAPE": key.NameEscape,
+	"HOME": key.NameHome, "END": key.NameEnd, "PAGEUP": key.NamePageUp, "PAGEDOWN": key.NamePageDown,
+	"BACKSPACE": key.NameDeleteBackward, "DELETE": key.NameDeleteForward, "DEL": key.NameDeleteForward,
+	"TAB": key.NameTab, "SPACE": key.NameSpace, "PLUS": "+",
+}
+
+// In is an option putting a shortcut in a scope, so that it is only active when the scope is drawn
+func In(s *ScopeDef) ShortcutOption {
+	return func(sc *shortcut) {
+		sc.scope = s
+	}
+}
+
+// Desc is an option giving a description of a shortcut, used in help pages
+func Desc(s string) ShortcutOption {
+	return func(sc *shortcut) {
+		sc.desc = s
+	}
+}
+
+func (o ShortcutOption) apply(cfg interface{}) {
+	o(cfg.(*shortcut))
+}
+
+// Scope returns a scope drawing the widget w, typically a form or a dialog.
+// Shortcuts given the option In(scope) are active only in frames where the scope
+// is drawn and enabled. A form is disabled while a dialog is shown, so its shortcuts
+// are then replaced by the shortcuts of the dialog.
+func Scope(w Wid) *ScopeDef {
+	return &ScopeDef{w: w, frame: -1}
+}
+
+// Layout draws the widget of the scope, and activates its shortcuts
+func (s *ScopeDef) Layout(gtx C) D {
+	if gtx.Enabled() {
+		s.mark()
+	}
+	if s.w == nil {
+		return D{}
+	}
+	return s.w(gtx)
+}
+
+// mark activates the shortcuts of the scope for the current frame
+func (s *ScopeDef) mark() {
+	shortcutMu.Lock()
+	defer shortcutMu.Unlock()
+	shortcutOrder++
+	s.frame, s.order = shortcutFrame, shortcutOrder
+}
+
+// parseShortcut converts a text like "Ctrl+S", "Ctrl+Shift+Z" or "F5" to a key name and modifiers.
+// Ctrl is the Command key on macOS.
+func parseShortcut(s string) (key.Name, key.Modifiers, error) {
+	parts := strings.Split(s, "+")
+	if strings.HasSuffix(s, "++") {
+		// The key itself is the plus sign
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	var mods key.Modifiers
+	for _, p := range parts[:len(parts)-1] {
+		switch strings.ToUpper(strings.TrimSpace(p)) {
+		case "CTRL", "CMD", "COMMAND":
+			mods |= key.ModShortcut
+		case "SHIFT":
+			mods |= key.ModShift
+		case "ALT", "OPTION":
+			mods |= key.ModAlt
+		case "SUPER", "META", "WIN":
+			mods |= key.ModSuper
+		default:
+			return "", 0, fmt.Errorf("unknown modifier %q in shortcut %q", p, s)
+		}
+	}
+	k := strings.TrimSpace(parts[len(parts)-1])
+	if k == "" {
+		return "", 0, fmt.Errorf("missing key in shortcut %q", s)
+	}
+	if n, ok := keyNames[strings.ToUpper(k)]; ok {
+		return n, mods, nil
+	}
+	return key.Name(strings.ToUpper(k)), mods, nil
+}
+
+// Shortcut registers a key combination like "Ctrl+S", "Ctrl+Shift+Z", "Alt+F" or "F5", that calls do
+// when it is pressed and not used by the focused widget. Without the In() option, the shortcut is
+// active whenever no dialog is shown. An error is returned if the keys can not be parsed, or if they
+// are already used in the same scope. When the same keys are used in several active scopes,
+// the scope drawn last is used, and scoped shortcuts take precedence over the others.
+// The function returned removes the shortcut, like when the form using it is rebuilt.
+func Shortcut(keys string, do func(), options ...Option) (remove func(), err error) {
+	name, mods, err := parseShortcut(keys)
+	if err != nil {
+		return func() {}, err
+	}
+	s := &shortcut{name: name, mods: mods, text: keys, do: do}
+	for _, option := range options {
+		option.apply(s)
+	}
+	shortcutMu.Lock()
+	defer shortcutMu.Unlock()
+	for _, o := range shortcuts {
+		if o.name == name && o.mods == mods && o.scope == s.scope {
+			if o.desc != "" {
+				return func() {}, fmt.Errorf("shortcut %q is already used for %q", keys, o.desc)
+			}
+			return func() {}, fmt.Errorf("shortcut %q is already used as %q", keys, o.text)
+		}
+	}
+	shortcuts = append(shortcuts, s)
+	return func() { removeShortcuts(func(o *shortcut) bool { return o == s }) }, nil
+}
+
+// RemoveShortcuts removes all shortcuts registered in the scope. It is used when
+// a form or dialog is rebuilt, and registers its shortcuts again.
+func (s *ScopeDef) RemoveShortcuts() {
+	removeShortcuts(func(o *shortcut) bool { return o.scope == s })
+}
+
+// removeShortcuts removes the shortcuts matching f
+func removeShortcuts(f func(*shortcut) bool) {
+	shortcutMu.Lock()
+	defer shortcutMu.Unlock()
+	shortcuts = slices.DeleteFunc(shortcuts, f)
+}
+
+// Shortcuts returns all registered shortcuts, in the order they were registered.
+// Active is true for the shortcuts that could be used in the last frame.
+func Shortcuts() []ShortcutInfo {
+	shortcutMu.Lock()
+	defer shortcutMu.Unlock()
+	list := make([]ShortcutInfo, len(shortcuts))
+	for i, s := range shortcuts {
+		list[i] = ShortcutInfo{Keys: s.text, Desc: s.desc, Active: s.active}
+	}
+	return list
+}
+
+// handleShortcuts calls the functions registered for the keys pressed. It is called from Run,
+// after the form and dialog are drawn, so the focused widget gets the keys first.
+func handleShortcuts(gtx C) {
+	shown := dialogShown()
+	shortcutMu.Lock()
+	var list []*shortcut
+	for _, s := range shortcuts {
+		if s.scope == nil {
+			s.active = !shown
+		} else {
+			s.active = s.scope.frame == shortcutFrame
+		}
+		if s.active {
+			list = append(list, s)
+		}
+	}
+	shortcutFrame++
+	shortcutOrder = 0
+	shortcutMu.Unlock()
+	if len(list) == 0 {
+		return
+	}
+	filters := make([]event.Filter, len(list))
+	for i, s := range list {
+		filters[i] = key.Filter{Name: s.name, Required: s.mods}
+	}
+	for {
+		ev, ok := gtx.Event(filters...)
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+		// Find the matching shortcut in the scope drawn last
+		var found *shortcut
+		for _, s := range list {
+			if s.name == e.Name && s.mods == e.Modifiers && (found == nil || order(s) > order(found)) {
+				found = s
+			}
+		}
+		if found != nil {
+			found.do()
+			// The function will typically change the form
+			gtx.Execute(op.InvalidateCmd{})
+		}
+	}
+}
+
+// order gives the precedence of a shortcut. Shortcuts without a scope come first.
+func order(s *shortcut) int {
+	if s.scope == nil {
+		return 0
+	}
+	return s.scope.order
+}
@@ -201,6 +201,8 @@ func (s *SliderStyle) setValue() {
 		s.pos = 1.0
 	}
 	GuiLock.Lock()
+	old := *s.Value
 	*s.Value = s.pos*(s.max-s.min) + s.min
+	s.record(s.Value, old)
 	GuiLock.Unlock()
 }
@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// SnackbarDef is a short message shown at the bottom of the window, with an optional action
+type SnackbarDef struct {
+	Base
+	text     string
+	action   string
+	duration time.Duration
+	anim     VisibilityAnimation
+	// shown is the time the snackbar was fully visible
+	shown       time.Time
+	actionClick gesture.Click
+	closeClick  gesture.Click
+}
+
+// NotifyOption is options specific to notifications
+type NotifyOption func(*SnackbarDef)
+
+const (
+	snackAnimation = 250 * time.Millisecond
+	// NotifyTimeDefault is the time a notification is shown when no NotifyTime() option is given
+	NotifyTimeDefault = 4 * time.Second
+)
+
+var (
+	snackMu   sync.Mutex
+	snackbars []*SnackbarDef
+	closeIcon *Icon
+)
+
+// NotifyAction is an option giving a notification an action button with the label. The function
+// do is called when the button is clicked, and the notification is then dismissed.
+func NotifyAction(label string, do func()) NotifyOption {
+	return func(s *SnackbarDef) {
+		s.action = label
+		s.onUserChange = do
+	}
+}
+
+// NotifyTime is an option giving the time a notification is shown. With a zero time,
+// it is shown with a close button until it is dismissed.
+func NotifyTime(d time.Duration) NotifyOption {
+	return func(s *SnackbarDef) {
+		s.duration = d
+	}
+}
+
+func (o NotifyOption) apply(cfg interface{}) {
+	o(cfg.(*SnackbarDef))
+}
+
+// Notify queues a snackbar with the text, shown at the bottom of the window after the notifications
+// queued before it. The role gives the severity, like Role(Error), and the default has the inverse
+// Surface colors. Notify can be called from any goroutine.
+func Notify(text string, options ...Option) {
+	s := &SnackbarDef{
+		Base: Base{
+			role:      Surface,
+			FontScale: 1.0,
+		},
+		text:     text,
+		duration: NotifyTimeDefault,
+		anim:     VisibilityAnimation{Duration: snackAnimation, State: Invisible},
+	}
+	for _, option := range options {
+		option.apply(s)
+	}
+	snackMu.Lock()
+	snackbars = append(snackbars, s)
+	snackMu.Unlock()
+	if invalidate != nil {
+		Invalidate()
+	}
+}
+
+// colors returns the background, text and action colors of the snackbar
+func (s *SnackbarDef) colors() (bg, fg, action color.NRGBA) {
+	if s.bgColor != nil || s.fgColor != nil || s.role != Surface {
+		return s.Bg(), s.Fg(), s.Fg()
+	}
+	return s.th.Fg[Surface], s.th.Bg[Surface], s.th.Bg[PrimaryContainer]
+}
+
+// dismiss starts hiding the snackbar
+func (s *SnackbarDef) dismiss(gtx C) {
+	if s.anim.State == Appearing || s.anim.State == Visible {
+		s.anim.Disappear(gtx.Now)
+		gtx.Execute(op.InvalidateCmd{})
+	}
+}
+
+func (s *SnackbarDef) handleEvents(gtx C) {
+	for {
+		e, ok := s.actionClick.Update(gtx.Source)
+		if !ok {
+			break
+		}
+		if e.Kind == gesture.KindClick {
+			if s.onUserChange != nil {
+				s.onUserChange()
+			}
+			s.dismiss(gtx)
+		}
+	}
+	for {
+		e, ok := s.closeClick.Update(gtx.Source)
+		if !ok {
+			break
+		}
+		if e.Kind == gesture.KindClick {
+			s.dismiss(gtx)
+		}
+	}
+}
+
+// label returns the recorded text with the color
+func (s *SnackbarDef) label(gtx C, txt string, weight font.Weight, col color.NRGBA, maxX int) (op.CallOp, D) {
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(maxX, inf)}
+	m := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	colorCall := m.Stop()
+	f := *s.Font
+	f.Weight = weight
+	m = op.Record(gtx.Ops)
+	d := widget.Label{MaxLines: 2}.Layout(c, s.th.Shaper, f, s.th.TextSize*unit.Sp(s.FontScale), txt, colorCall)
+	return m.Stop(), d
+}
+
+// Layout draws the snackbar at the bottom of the window, sliding in and out
+func (s *SnackbarDef) Layout(gtx C) D {
+	s.handleEvents(gtx)
+	size := gtx.Constraints.Max
+	margin, pad := Px(gtx, unit.Dp(16)), Px(gtx, unit.Dp(16))
+	bg, fg, actionCol := s.colors()
+
+	// The action and close button are to the right of the text
+	var actionCall op.CallOp
+	var actionD D
+	buttonsW := Px(gtx, unit.Dp(8))
+	if s.action != "" {
+		actionCall, actionD = s.label(gtx, s.action, font.Medium, actionCol, inf)
+		buttonsW += actionD.Size.X + 2*Px(gtx, unit.Dp(12))
+	}
+	iconSize := Px(gtx, unit.Dp(24))
+	if s.duration == 0 {
+		buttonsW += iconSize + Px(gtx, unit.Dp(16))
+	}
+	width := Min(size.X-2*margin, Px(gtx, unit.Dp(600)))
+	textCall, textD := s.label(gtx, s.text, font.Normal, fg, Max(0, width-pad-buttonsW))
+	width = Min(width, Max(Px(gtx, unit.Dp(344)), pad+textD.Size.X+buttonsW))
+	height := Max(Px(gtx, unit.Dp(48)), textD.Size.Y+2*Px(gtx, unit.Dp(14)))
+
+	r := s.anim.Revealed(gtx)
+	y := size.Y - int(r*float32(height+margin))
+	defer op.Offset(image.Pt((size.X-width)/2, y)).Push(gtx.Ops).Pop()
+	rect := image.Rect(0, 0, width, height)
+	rr := Px(gtx, unit.Dp(4))
+	DrawShadow(gtx, rect, rr, Px(gtx, unit.Dp(6)))
+	paint.FillShape(gtx.Ops, bg, clip.UniformRRect(rect, rr).Op(gtx.Ops))
+	o := op.Offset(image.Pt(pad, (height-textD.Size.Y)/2)).Push(gtx.Ops)
+	textCall.Add(gtx.Ops)
+	o.Pop()
+
+	x := width - Px(gtx, unit.Dp(8))
+	if s.duration == 0 {
+		x -= iconSize + Px(gtx, unit.Dp(16))
+		c := gtx
+		c.Constraints.Min.X = iconSize
+		o := op.Offset(image.Pt(x+Px(gtx, unit.Dp(8)), (height-iconSize)/2)).Push(gtx.Ops)
+		closeIcon.Layout(c, fg)
+		o.Pop()
+		a := clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+iconSize+Px(gtx, unit.Dp(16)), height)}.Push(gtx.Ops)
+		s.closeClick.Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		a.Pop()
+	}
+	if s.action != "" {
+		w := actionD.Size.X + 2*Px(gtx, unit.Dp(12))
+		x -= w
+		o := op.Offset(image.Pt(x+Px(gtx, unit.Dp(12)), (height-actionD.Size.Y)/2)).Push(gtx.Ops)
+		actionCall.Add(gtx.Ops)
+		o.Pop()
+		a := clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+w, height)}.Push(gtx.Ops)
+		s.actionClick.Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		a.Pop()
+	}
+	return D{Size: image.Pt(width, height)}
+}
+
+// layoutSnackbars draws the first queued notification, and removes it when it has been
+// shown for its duration. It is called from Run, after the form and dialog are drawn.
+func layoutSnackbars(gtx C, th *Theme) {
+	snackMu.Lock()
+	if len(snackbars) == 0 {
+		snackMu.Unlock()
+		return
+	}
+	s := snackbars[0]
+	snackMu.Unlock()
+	if s.th == nil {
+		s.th = th
+		if s.Font == nil {
+			s.Font = &th.DefaultFont
+		}
+	}
+	switch s.anim.State {
+	case Invisible:
+		if !s.shown.IsZero() {
+			// The snackbar has disappeared, and the next one is shown the next frame
+			snackMu.Lock()
+			snackbars = snackbars[1:]
+			snackMu.Unlock()
+			gtx.Execute(op.InvalidateCmd{})
+			return
+		}
+		s.anim.Appear(gtx.Now)
+		s.shown = gtx.Now.Add(snackAnimation)
+	case Visible:
+		if s.duration > 0 {
+			if end := s.shown.Add(s.duration); !gtx.Now.Before(end) {
+				s.dismiss(gtx)
+			} else {
+				gtx.Execute(op.InvalidateCmd{At: end})
+			}
+		}
+	}
+	c := gtx
+	c.Constraints = layout.Exact(gtx.Constraints.Max)
+	s.Layout(c)
+}
+
+func init() {
+	closeIcon, _ = NewIcon(icons.NavigationClose)
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// StatusBarDef is a bar at the bottom of a window, with sections to the left,
+// in the center and to the right.
+type StatusBarDef struct {
+	Base
+	left, center, right []Wid
+}
+
+// StatusOption is options specific to status bars
+type StatusOption func(*StatusBarDef)
+
+// StatusLeft is an option giving the widgets at the left end of a status bar
+func StatusLeft(w ...Wid) StatusOption {
+	return func(s *StatusBarDef) {
+		s.left = w
+	}
+}
+
+// StatusCenter is an option giving the widgets in the center of a status bar
+func StatusCenter(w ...Wid) StatusOption {
+	return func(s *StatusBarDef) {
+		s.center = w
+	}
+}
+
+// StatusRight is an option giving the widgets at the right end of a status bar
+func StatusRight(w ...Wid) StatusOption {
+	return func(s *StatusBarDef) {
+		s.right = w
+	}
+}
+
+func (o StatusOption) apply(cfg interface{}) {
+	o(cfg.(*StatusBarDef))
+}
+
+// StatusBar returns a bar with a SurfaceContainer background, and a divider at the top.
+// The widgets are given with the StatusLeft, StatusCenter and StatusRight options. They are typically
+// labels, a ProgressBar with a width given by W(), and indicators.
+func StatusBar(th *Theme, options ...Option) layout.Widget {
+	s := &StatusBarDef{
+		Base: Base{
+			th:        th,
+			role:      SurfaceContainer,
+			padding:   layout.Inset{Left: 8, Right: 8, Top: 2, Bottom: 2},
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+	}
+	for _, option := range options {
+		option.apply(s)
+	}
+	return s.Layout
+}
+
+// section records the widgets of a section, and returns their sizes
+func (s *StatusBarDef) section(gtx C, ws []Wid) ([]op.CallOp, []image.Point, int) {
+	calls := make([]op.CallOp, len(ws))
+	sizes := make([]image.Point, len(ws))
+	gap := Px(gtx, unit.Dp(16))
+	w := 0
+	for i, wid := range ws {
+		m := op.Record(gtx.Ops)
+		sizes[i] = wid(gtx).Size
+		calls[i] = m.Stop()
+		if i > 0 {
+			w += gap
+		}
+		w += sizes[i].X
+	}
+	return calls, sizes, w
+}
+
+// Layout draws the sections. The center section is centered in the bar when there is space for it.
+func (s *StatusBarDef) Layout(gtx C) D {
+	pt, pb, pl, pr := ScaleInset(gtx, s.padding)
+	width := gtx.Constraints.Max.X
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(width, gtx.Constraints.Max.Y)}
+	lc, ls, lw := s.section(c, s.left)
+	cc, cs, cw := s.section(c, s.center)
+	rc, rs, rw := s.section(c, s.right)
+	height := 0
+	for _, sizes := range [][]image.Point{ls, cs, rs} {
+		for _, sz := range sizes {
+			height = Max(height, sz.Y)
+		}
+	}
+	height += pt + pb
+	paint.FillShape(gtx.Ops, s.Bg(), clip.Rect{Max: image.Pt(width, height)}.Op())
+	dh := Max(1, Px(gtx, unit.Dp(1)))
+	paint.FillShape(gtx.Ops, s.th.Bg[OutlineVariant], clip.Rect{Max: image.Pt(width, dh)}.Op())
+	gap := Px(gtx, unit.Dp(16))
+	draw := func(calls []op.CallOp, sizes []image.Point, x int) {
+		for i := range calls {
+			o := op.Offset(image.Pt(x, pt+(height-pt-pb-sizes[i].Y)/2)).Push(gtx.Ops)
+			calls[i].Add(gtx.Ops)
+			o.Pop()
+			x += sizes[i].X + gap
+		}
+	}
+	draw(lc, ls, pl)
+	draw(rc, rs, width-pr-rw)
+	// The center section is moved aside if it would overlap the others
+	x := (width - cw) / 2
+	x = Min(x, width-pr-rw-gap-cw)
+	x = Max(x, pl+lw+gap)
+	draw(cc, cs, x)
+	return D{Size: image.Pt(width, height)}
+}
+
+// IndicatorDef is a small colored dot with a text, used in status bars
+type IndicatorDef struct {
+	Base
+	text string
+	on   *bool
+}
+
+// Indicator returns a dot followed by the text. The dot has the color of the role (Primary by default)
+// when the value is true, and the Outline color when it is false.
+func Indicator(th *Theme, text string, on *bool, options ...Option) layout.Widget {
+	d := &IndicatorDef{
+		Base: Base{
+			th:        th,
+			role:      Primary,
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		text: text,
+		on:   on,
+	}
+	for _, option := range options {
+		option.apply(d)
+	}
+	return d.Layout
+}
+
+// Layout draws the indicator
+func (d *IndicatorDef) Layout(gtx C) D {
+	GuiLock.RLock()
+	on := *d.on
+	GuiLock.RUnlock()
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	m := op.Record(gtx.Ops)
+	col := d.th.Fg[SurfaceContainer]
+	if !gtx.Enabled() {
+		col = Disabled(col)
+	}
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	colorCall := m.Stop()
+	m = op.Record(gtx.Ops)
+	dims := widget.Label{MaxLines: 1}.Layout(c, d.th.Shaper, *d.Font, d.th.TextSize*unit.Sp(d.FontScale), d.text, colorCall)
+	textCall := m.Stop()
+	sz := Px(gtx, unit.Dp(8))
+	gap := Px(gtx, unit.Dp(6))
+	dot := d.th.Fg[Outline]
+	if on {
+		dot = d.Bg()
+	}
+	y := (dims.Size.Y - sz) / 2
+	paint.FillShape(gtx.Ops, dot, clip.Ellipse(image.Rect(0, y, sz, y+sz)).Op(gtx.Ops))
+	o := op.Offset(image.Pt(sz+gap, 0)).Push(gtx.Ops)
+	textCall.Add(gtx.Ops)
+	o.Pop()
+	return D{Size: image.Pt(sz+gap+dims.Size.X, dims.Size.Y), Baseline: dims.Baseline}
+}
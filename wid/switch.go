@@ -71,6 +71,7 @@ func (s *SwitchDef) Layout(gtx C) D {
 	if *s.StatePtr != s.sw.Value {
 		GuiLock.Lock()
 		*s.StatePtr = s.sw.Value
+		s.record(s.StatePtr, !s.sw.Value)
 		if s.onUserChange != nil {
 			s.onUserChange()
 		}
@@ -0,0 +1,419 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"time"
+
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// Tab is one tab in a Tabs widget. Text and/or Icon is shown in the tab row,
+// and Content is shown below it when the tab is selected.
+type Tab struct {
+	Text    string
+	Icon    *Icon
+	Content layout.Widget
+}
+
+// TabsDef is a row of tabs, with the content of the selected tab below.
+type TabsDef struct {
+	Base
+	index     *int
+	tabs      interface{}
+	secondary bool
+	onClose   func(i int)
+	clicks    []gesture.Click
+	closes    []gesture.Click
+	focused   bool
+	// offset is the scroll position when the tabs are wider than the window
+	offset int
+	shown  int
+	// The indicator moves from indFrom to indTo, and ind is its current position.
+	// The positions are relative to the start of the first tab.
+	ind, indFrom, indTo [2]float32
+	indStart            time.Time
+}
+
+// tabAnimation is the time used to move the indicator to a new tab
+const tabAnimation = 250 * time.Millisecond
+
+// TabOption is options specific to Tabs
+type TabOption func(*TabsDef)
+
+// SecondaryTabs is an option giving the Material 3 secondary tab style, used for
+// tabs within a page. The indicator spans the whole tab, and icons are placed before the text.
+func SecondaryTabs() TabOption {
+	return func(t *TabsDef) {
+		t.secondary = true
+	}
+}
+
+// OnClose is an option adding a close button to each tab. The function is called with
+// the index of the tab to close, and should remove it from the tabs.
+func OnClose(f func(i int)) TabOption {
+	return func(t *TabsDef) {
+		t.onClose = f
+	}
+}
+
+func (o TabOption) apply(cfg interface{}) {
+	o(cfg.(*TabsDef))
+}
+
+// Tabs returns a row of tabs, with the content of the selected tab below. The index of the selected
+// tab is read from and written to index. The tabs can be given as a pointer to a slice, so that
+// tabs can be added and closed. When the tabs are wider than the window, they can be scrolled.
+// The arrow keys, Home and End select tabs when the row has focus.
+func Tabs[V []Tab | *[]Tab](th *Theme, index *int, tabs V, options ...Option) layout.Widget {
+	t := &TabsDef{
+		Base: Base{
+			th:        th,
+			role:      Surface,
+			padding:   layout.Inset{Left: 16, Right: 16},
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		index: index,
+		tabs:  tabs,
+		shown: -1,
+	}
+	for _, option := range options {
+		option.apply(t)
+	}
+	return t.Layout
+}
+
+// list returns the tabs, which must be read with GuiLock held
+func (t *TabsDef) list() []Tab {
+	if p, ok := t.tabs.(*[]Tab); ok {
+		return *p
+	}
+	return t.tabs.([]Tab)
+}
+
+// setIndex selects tab i, and calls the Do() function. The selection is navigation,
+// not form data, so it is not recorded in the undo history.
+func (t *TabsDef) setIndex(i int) {
+	GuiLock.Lock()
+	old := *t.index
+	*t.index = i
+	GuiLock.Unlock()
+	if old != i && t.onUserChange != nil {
+		t.onUserChange()
+	}
+}
+
+func (t *TabsDef) handleEvents(gtx C, n, sel, maxOffset int) {
+	for i := 0; i < n && i < len(t.clicks); i++ {
+		for {
+			e, ok := t.clicks[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindPress && e.Source == pointer.Mouse {
+				gtx.Execute(key.FocusCmd{Tag: t})
+			}
+			if e.Kind == gesture.KindClick {
+				t.setIndex(i)
+			}
+		}
+		for {
+			e, ok := t.closes[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if e.Kind == gesture.KindClick && t.onClose != nil {
+				t.onClose(i)
+				// The tabs are changed, so a new frame is needed
+				gtx.Execute(op.InvalidateCmd{})
+			}
+		}
+	}
+	for {
+		ev, ok := gtx.Event(
+			key.FocusFilter{Target: t},
+			key.Filter{Focus: t, Name: key.NameLeftArrow},
+			key.Filter{Focus: t, Name: key.NameRightArrow},
+			key.Filter{Focus: t, Name: key.NameHome},
+			key.Filter{Focus: t, Name: key.NameEnd},
+			key.Filter{Focus: t, Name: "W", Required: key.ModShortcut},
+			pointer.Filter{Target: &t.offset, Kinds: pointer.Scroll,
+				ScrollX: pointer.ScrollRange{Min: -t.offset, Max: maxOffset - t.offset},
+				ScrollY: pointer.ScrollRange{Min: -t.offset, Max: maxOffset - t.offset}},
+		)
+		if !ok {
+			break
+		}
+		switch e := ev.(type) {
+		case key.FocusEvent:
+			t.focused = e.Focus
+		case key.Event:
+			if e.State != key.Press || n == 0 {
+				break
+			}
+			switch e.Name {
+			case key.NameLeftArrow:
+				t.setIndex((sel + n - 1) % n)
+			case key.NameRightArrow:
+				t.setIndex((sel + 1) % n)
+			case key.NameHome:
+				t.setIndex(0)
+			case key.NameEnd:
+				t.setIndex(n - 1)
+			case "W":
+				if t.onClose != nil {
+					t.onClose(sel)
+					gtx.Execute(op.InvalidateCmd{})
+				}
+			}
+		case pointer.Event:
+			t.offset += int(e.Scroll.X + e.Scroll.Y)
+		}
+	}
+}
+
+// tabSize returns the size of the contents of a tab without padding, and the size of the text
+func (t *TabsDef) tabSize(gtx C, tab *Tab, textSize unit.Sp) (image.Point, image.Point) {
+	m := op.Record(gtx.Ops)
+	text := t.label(gtx, tab.Text, textSize, op.CallOp{}).Size
+	m.Stop()
+	sz := text
+	icon := Px(gtx, unit.Dp(24))
+	if tab.Icon != nil {
+		if t.secondary {
+			sz.X += icon + Px(gtx, unit.Dp(8))
+			sz.Y = Max(sz.Y, icon)
+		} else {
+			sz.X = Max(sz.X, icon)
+			sz.Y += icon + Px(gtx, unit.Dp(2))
+		}
+	}
+	if t.onClose != nil {
+		sz.X += Px(gtx, unit.Dp(26))
+	}
+	return sz, text
+}
+
+func (t *TabsDef) label(gtx C, s string, textSize unit.Sp, col op.CallOp) D {
+	if s == "" {
+		return D{}
+	}
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(inf, inf)}
+	return widget.Label{MaxLines: 1}.Layout(c, t.th.Shaper, *t.Font, textSize, s, col)
+}
+
+// moveIndicator starts the animation of the indicator when the selected tab is moved
+func (t *TabsDef) moveIndicator(gtx C, x0, x1 float32) {
+	if t.indTo != [2]float32{x0, x1} {
+		t.indFrom, t.indTo, t.indStart = t.ind, [2]float32{x0, x1}, gtx.Now
+		if t.ind == [2]float32{} {
+			// No animation the first time
+			t.indFrom = t.indTo
+		}
+	}
+	p := float32(gtx.Now.Sub(t.indStart)) / float32(tabAnimation)
+	if p < 1 {
+		gtx.Execute(op.InvalidateCmd{})
+		// Ease out
+		p = 1 - (1-p)*(1-p)
+	} else {
+		p = 1
+	}
+	for k := range t.ind {
+		t.ind[k] = t.indFrom[k] + (t.indTo[k]-t.indFrom[k])*p
+	}
+}
+
+// Layout draws the tab row, and the content of the selected tab below it
+func (t *TabsDef) Layout(gtx C) D {
+	GuiLock.RLock()
+	tabs := append([]Tab(nil), t.list()...)
+	sel := *t.index
+	GuiLock.RUnlock()
+	n := len(tabs)
+	for len(t.clicks) < n {
+		t.clicks = append(t.clicks, gesture.Click{})
+		t.closes = append(t.closes, gesture.Click{})
+	}
+
+	textSize := t.th.TextSize * unit.Sp(t.FontScale)
+	pl, pr := Px(gtx, t.padding.Left), Px(gtx, t.padding.Right)
+	width := gtx.Constraints.Max.X
+	// Find the width of each tab. If they fit, all tabs get the same width.
+	sizes := make([]image.Point, n)
+	texts := make([]image.Point, n)
+	widest, hasIcon := 0, false
+	for i := range tabs {
+		sizes[i], texts[i] = t.tabSize(gtx, &tabs[i], textSize)
+		widest = Max(widest, sizes[i].X+pl+pr)
+		hasIcon = hasIcon || tabs[i].Icon != nil
+	}
+	height := Px(gtx, unit.Dp(48))
+	if hasIcon && !t.secondary {
+		height = Px(gtx, unit.Dp(64))
+	}
+	xs := make([]int, n+1)
+	for i := range tabs {
+		w := sizes[i].X + pl + pr
+		if widest*n <= width {
+			w = width / n
+		}
+		xs[i+1] = xs[i] + w
+	}
+	maxOffset := Max(0, xs[n]-width)
+
+	t.handleEvents(gtx, n, sel, maxOffset)
+	GuiLock.RLock()
+	sel = *t.index
+	GuiLock.RUnlock()
+	if n > 0 && (sel < 0 || sel >= n) {
+		// The selected tab is closed
+		sel = Clamp(sel, 0, n-1)
+		t.setIndex(sel)
+	}
+	// Scroll the selected tab into view when it is changed
+	if sel != t.shown && n > 0 {
+		if xs[sel] < t.offset {
+			t.offset = xs[sel]
+		} else if xs[sel+1] > t.offset+width {
+			t.offset = xs[sel+1] - width
+		}
+		t.shown = sel
+	}
+	t.offset = Clamp(t.offset, 0, maxOffset)
+
+	// Background and divider
+	row := image.Rect(0, 0, width, height)
+	paint.FillShape(gtx.Ops, t.Bg(), clip.Rect(row).Op())
+	dh := Max(1, Px(gtx, unit.Dp(1)))
+	paint.FillShape(gtx.Ops, t.th.Bg[OutlineVariant], clip.Rect{Min: image.Pt(0, height-dh), Max: image.Pt(width, height)}.Op())
+	cl := clip.Rect(row).Push(gtx.Ops)
+	event.Op(gtx.Ops, &t.offset)
+	event.Op(gtx.Ops, t)
+	icon := Px(gtx, unit.Dp(24))
+	closeW := 0
+	if t.onClose != nil {
+		closeW = Px(gtx, unit.Dp(26))
+	}
+	for i := range tabs {
+		tab := &tabs[i]
+		r := image.Rect(xs[i]-t.offset, 0, xs[i+1]-t.offset, height)
+		sz := sizes[i]
+		if i == sel {
+			// The primary indicator has the width of the content, the secondary the width of the tab.
+			x0, x1 := float32(xs[i]), float32(xs[i+1])
+			if !t.secondary {
+				x0 = float32(xs[i] + (r.Dx()-sz.X)/2)
+				x1 = x0 + float32(sz.X-closeW)
+			}
+			t.moveIndicator(gtx, x0, x1)
+		}
+		if r.Max.X < 0 || r.Min.X > width {
+			continue
+		}
+		col := t.th.Fg[SurfaceVariant]
+		if i == sel && t.secondary {
+			col = t.th.Fg[Surface]
+		} else if i == sel {
+			col = t.th.Bg[Primary]
+		}
+		if !gtx.Enabled() {
+			col = Disabled(col)
+		}
+		// Hover and press state layer
+		if t.clicks[i].Pressed() {
+			paint.FillShape(gtx.Ops, MulAlpha(col, 30), clip.Rect(r).Op())
+		} else if t.clicks[i].Hovered() {
+			paint.FillShape(gtx.Ops, MulAlpha(col, 20), clip.Rect(r).Op())
+		}
+		// The content is centered in the tab. Primary tabs have the icon above the text.
+		x := r.Min.X + (r.Dx()-sz.X)/2
+		cw := sz.X - closeW
+		ip := image.Pt(x, (height-icon)/2)
+		tp := image.Pt(x, (height-texts[i].Y)/2)
+		if tab.Icon != nil && t.secondary {
+			tp.X += icon + Px(gtx, unit.Dp(8))
+		} else if tab.Icon != nil {
+			ip = image.Pt(x+(cw-icon)/2, (height-sz.Y)/2)
+			tp = image.Pt(x+(cw-texts[i].X)/2, ip.Y+icon+Px(gtx, unit.Dp(2)))
+		}
+		if tab.Icon != nil {
+			o := op.Offset(ip).Push(gtx.Ops)
+			ic := gtx
+			ic.Constraints = layout.Exact(image.Pt(icon, icon))
+			tab.Icon.Layout(ic, col)
+			o.Pop()
+		}
+		m := op.Record(gtx.Ops)
+		paint.ColorOp{Color: col}.Add(gtx.Ops)
+		colorCall := m.Stop()
+		o := op.Offset(tp).Push(gtx.Ops)
+		t.label(gtx, tab.Text, textSize, colorCall)
+		o.Pop()
+		a := clip.Rect(r).Push(gtx.Ops)
+		t.clicks[i].Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		a.Pop()
+		if t.onClose != nil {
+			// The close button is at the end of the content
+			cs := Px(gtx, unit.Dp(18))
+			cr := image.Rect(x+sz.X-cs, (height-cs)/2, x+sz.X, (height+cs)/2)
+			hr := cr.Inset(-Px(gtx, unit.Dp(3)))
+			if t.closes[i].Hovered() {
+				paint.FillShape(gtx.Ops, MulAlpha(col, 40), clip.Ellipse(hr).Op(gtx.Ops))
+			}
+			o := op.Offset(cr.Min).Push(gtx.Ops)
+			ic := gtx
+			ic.Constraints = layout.Exact(cr.Size())
+			clearIcon.Layout(ic, col)
+			o.Pop()
+			a := clip.Rect(hr).Push(gtx.Ops)
+			t.closes[i].Add(gtx.Ops)
+			pointer.CursorPointer.Add(gtx.Ops)
+			a.Pop()
+		}
+		if i == sel && t.focused {
+			paintBorder(gtx, r.Inset(Px(gtx, unit.Dp(2))), t.th.Bg[Primary], float32(Px(gtx, unit.Dp(2))), Px(gtx, unit.Dp(4)))
+		}
+	}
+	if n > 0 {
+		// The active indicator, with rounded top corners for primary tabs
+		ih := Px(gtx, unit.Dp(3))
+		rr := ih
+		if t.secondary {
+			ih, rr = Px(gtx, unit.Dp(2)), 0
+		}
+		ir := image.Rect(int(t.ind[0])-t.offset, height-ih, int(t.ind[1])-t.offset, height+rr)
+		col := t.th.Bg[Primary]
+		if !gtx.Enabled() {
+			col = Disabled(col)
+		}
+		paint.FillShape(gtx.Ops, col, clip.UniformRRect(ir, rr).Op(gtx.Ops))
+	}
+	cl.Pop()
+
+	// Only the content of the selected tab is laid out
+	dims := D{Size: image.Pt(width, height)}
+	if n > 0 && tabs[sel].Content != nil {
+		c := gtx
+		c.Constraints.Min.Y = Max(0, c.Constraints.Min.Y-height)
+		c.Constraints.Max.Y = Max(0, c.Constraints.Max.Y-height)
+		o := op.Offset(image.Pt(0, height)).Push(gtx.Ops)
+		cd := tabs[sel].Content(c)
+		o.Pop()
+		dims.Size.Y += cd.Size.Y
+	}
+	return dims
+}
@@ -0,0 +1,466 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/gesture"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// FieldStyle selects how an Edit is drawn.
+// It is given as an option to Edit(), like wid.Edit(th, &name, wid.OutlinedField, wid.Lbl("Name"))
+type FieldStyle uint8
+
+const (
+	// PlainField is the original edit box, with an optional label to the left
+	PlainField FieldStyle = iota
+	// OutlinedField is the Material 3 outlined text field with a floating label
+	OutlinedField
+	// FilledField is the Material 3 filled text field with a floating label
+	FilledField
+)
+
+const (
+	// fieldLabelScale is the size of the floating label relative to the text size
+	fieldLabelScale = 0.75
+	// fieldAnimDuration is the time used for the label to float up or down
+	fieldAnimDuration = time.Millisecond * 150
+)
+
+var (
+	clearIcon         *Icon
+	visibilityIcon    *Icon
+	visibilityOffIcon *Icon
+)
+
+// fieldIcon is an icon inside a text field, with an optional click handler
+type fieldIcon struct {
+	icon    *Icon
+	click   gesture.Click
+	onClick func()
+}
+
+// handle will call the click handler for all pending clicks
+func (f *fieldIcon) handle(gtx C) {
+	for {
+		ev, ok := f.click.Update(gtx.Source)
+		if !ok {
+			break
+		}
+		if ev.Kind == gesture.KindClick && f.onClick != nil && gtx.Enabled() {
+			f.onClick()
+		}
+	}
+}
+
+// layout draws the icon with the given size and sets up the click area
+func (f *fieldIcon) layout(gtx C, size int, col color.NRGBA) {
+	c := gtx
+	c.Constraints.Min = image.Pt(size, size)
+	c.Constraints.Max = c.Constraints.Min
+	f.icon.Layout(c, col)
+	if f.onClick != nil {
+		defer clip.Rect{Max: image.Pt(size, size)}.Push(gtx.Ops).Pop()
+		f.click.Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+	}
+}
+
+// recordText lays out a single line of text into a macro, and returns the macro and its size
+func (e *EditDef) recordText(gtx C, s string, size unit.Sp, col color.NRGBA) (op.CallOp, D) {
+	macro := op.Record(gtx.Ops)
+	colMacro := op.Record(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	c := gtx
+	c.Constraints.Min = image.Point{}
+	dims := widget.Label{MaxLines: 1}.Layout(c, e.th.Shaper, *e.Font, size, s, colMacro.Stop())
+	return macro.Stop(), dims
+}
+
+// updateLabel starts the floating label animation when focus or content changes.
+func (e *EditDef) updateLabel(gtx C) float32 {
+	floated := gtx.Focused(&e.Editor) || e.Editor.Len() > 0
+	if e.labelAnim.Started.IsZero() {
+		// First frame, just show label at the correct position without animation
+		e.labelAnim.Started = gtx.Now
+		if floated {
+			e.labelAnim.State = Visible
+		} else {
+			e.labelAnim.State = Invisible
+		}
+	}
+	if !e.labelAnim.Animating() {
+		e.labelAnim.Duration = fieldAnimDuration
+	}
+	if floated {
+		e.labelAnim.Appear(gtx.Now)
+	} else {
+		e.labelAnim.Disappear(gtx.Now)
+	}
+	return e.labelAnim.Revealed(gtx)
+}
+
+// layoutField draws the edit as a Material 3 outlined or filled text field.
+func (e *EditDef) layoutField(gtx C) D {
+	mt, mb, ml, mr := ScaleInset(gtx, e.margin)
+	pt, pb, pl, _ := ScaleInset(gtx, e.padding)
+	e.leadIcon.handle(gtx)
+	e.trailIcon.handle(gtx)
+	if e.focusEditor {
+		gtx.Execute(key.FocusCmd{Tag: &e.Editor})
+		e.focusEditor = false
+	}
+	e.handleEvents(gtx)
+	e.updateValue(gtx)
+	e.validateText(gtx)
+	errText := e.errorText()
+	focused := gtx.Focused(&e.Editor)
+	f := e.updateLabel(gtx)
+	textSize := e.th.TextSize * unit.Sp(e.FontScale)
+	smallSize := textSize * fieldLabelScale
+	fg := e.Fg()
+	accent := e.th.Bg[Primary]
+	outline := e.outlineColor
+	// Read-only fields keep the text color, but have no hover effect and a weaker outline
+	hovered := e.hovered && !e.ReadOnly
+	if !gtx.Enabled() {
+		fg = Disabled(fg)
+		accent = fg
+	} else if errText != "" {
+		accent = e.th.Bg[Error]
+		outline = accent
+	} else if e.ReadOnly {
+		outline = e.th.Fg[OutlineVariant]
+	}
+	// Find the height of text lines
+	_, textDim := e.recordText(gtx, "Mg", textSize, fg)
+	_, smallDim := e.recordText(gtx, "Mg", smallSize, fg)
+	textH := textDim.Size.Y
+	smallH := smallDim.Size.Y
+
+	// Calculate total width, limited by the given width
+	width := Max(100, gtx.Constraints.Min.X) - ml - mr
+	if w := Px(gtx, e.width); w > 0 && w < width {
+		width = w
+	}
+	// Vertical layout of the box
+	top := 0
+	boxH := textH + 2*(pt+pb)
+	textY := (boxH - textH) / 2
+	floatY := -smallH / 2
+	if e.style == OutlinedField && e.label != "" {
+		top = smallH / 2
+	} else if e.style == FilledField && e.label != "" {
+		floatY = pt
+		textY = pt + smallH
+		boxH = textY + textH + pb + pt
+	}
+	restY := (boxH - textH) / 2
+
+	// Horizontal layout of the box
+	padX := Max(pl, textH/2)
+	iconSize := textH * 6 / 5
+	contentX := padX
+	if e.leadIcon.icon != nil {
+		contentX += iconSize + padX/2
+	}
+	trailW := 0
+	if e.trailIcon.icon != nil {
+		trailW = iconSize + padX/2
+	}
+	affixCol := MulAlpha(fg, 160)
+	showAffix := e.label == "" || f >= 1.0
+	prefixCall, prefixDim := e.recordText(gtx, e.prefix, textSize, affixCol)
+	suffixCall, suffixDim := e.recordText(gtx, e.suffix, textSize, affixCol)
+	editorX := contentX + prefixDim.Size.X
+	editorW := Max(0, width-editorX-padX-trailW-suffixDim.Size.X)
+
+	defer op.Offset(image.Pt(ml, mt+top)).Push(gtx.Ops).Pop()
+	box := image.Rect(0, 0, width, boxH)
+	rr := Min(Px(gtx, e.th.BorderCornerRadius), boxH/2)
+	bw := float32(Px(gtx, e.borderThickness))
+
+	// Filled fields have a background with rounded top corners
+	if e.style == FilledField {
+		bg := e.th.Bg[SurfaceContainerHighest]
+		if e.ReadOnly {
+			bg = e.th.Bg[SurfaceContainer]
+		} else if hovered && gtx.Enabled() {
+			bg = Interpolate(bg, fg, 0.08)
+		}
+		paint.FillShape(gtx.Ops, bg, clip.RRect{Rect: box, NW: rr, NE: rr}.Op(gtx.Ops))
+	}
+
+	// Now layout the editor itself
+	macro := op.Record(gtx.Ops)
+	paint.ColorOp{Color: fg}.Add(gtx.Ops)
+	textColorOps := macro.Stop()
+	macro = op.Record(gtx.Ops)
+	paint.ColorOp{Color: e.th.SelectionColor}.Add(gtx.Ops)
+	selectionColorOps := macro.Stop()
+	c := gtx
+	c.Constraints.Min = image.Pt(editorW, textH)
+	c.Constraints.Max = c.Constraints.Min
+	o := op.Offset(image.Pt(editorX, textY)).Push(gtx.Ops)
+	e.Editor.Layout(c, e.th.Shaper, *e.Font, textSize, textColorOps, selectionColorOps)
+	e.layoutGhost(c, textSize)
+	// The hint is shown in empty fields when the label has floated away
+	if e.Editor.Len() == 0 && e.hint != "" && (e.label == "" || (focused && f >= 1.0)) {
+		call, _ := e.recordText(c, e.hint, textSize, MulAlpha(fg, 110))
+		call.Add(gtx.Ops)
+	}
+	o.Pop()
+
+	// Prefix and suffix text is only shown together with the text
+	if showAffix && e.prefix != "" {
+		o := op.Offset(image.Pt(contentX, textY)).Push(gtx.Ops)
+		prefixCall.Add(gtx.Ops)
+		o.Pop()
+	}
+	if showAffix && e.suffix != "" {
+		o := op.Offset(image.Pt(editorX+editorW, textY)).Push(gtx.Ops)
+		suffixCall.Add(gtx.Ops)
+		o.Pop()
+	}
+
+	// Draw the label, interpolated between resting and floating position
+	gapStart, gapEnd := 0, 0
+	if e.label != "" {
+		lblCol := MulAlpha(fg, 200)
+		if focused || errText != "" {
+			lblCol = accent
+		}
+		size := textSize - (textSize-smallSize)*unit.Sp(f)
+		call, dim := e.recordText(gtx, e.label, size, lblCol)
+		floatX := contentX
+		if e.style == OutlinedField {
+			floatX = padX
+		}
+		x := contentX + int(f*float32(floatX-contentX))
+		y := restY + int(f*float32(floatY-restY))
+		o := op.Offset(image.Pt(x, y)).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		o.Pop()
+		// Leave a gap in the outline for the floating label
+		gp := Px(gtx, unit.Dp(4))
+		gapStart = x - gp
+		gapEnd = x - gp + int(f*float32(dim.Size.X+2*gp))
+	}
+
+	// Draw the outline or the bottom indicator line
+	switch {
+	case e.style == FilledField:
+		h := int(bw)
+		col := outline
+		if focused {
+			h *= 2
+			col = accent
+		} else if hovered && errText == "" {
+			col = fg
+		}
+		paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(0, boxH-h), Max: box.Max}.Op())
+	case e.borderThickness > 0:
+		if focused {
+			paintBorderGap(gtx, box, accent, bw*2, rr, gapStart, gapEnd)
+		} else if hovered && errText == "" {
+			paintBorderGap(gtx, box, fg, bw*3/2, rr, gapStart, gapEnd)
+		} else {
+			paintBorderGap(gtx, box, outline, bw, rr, gapStart, gapEnd)
+		}
+	}
+
+	// Leading and trailing icons
+	iconY := (boxH - iconSize) / 2
+	if textY != restY {
+		iconY = textY + (textH-iconSize)/2
+	}
+	if e.leadIcon.icon != nil {
+		o := op.Offset(image.Pt(padX, iconY)).Push(gtx.Ops)
+		e.leadIcon.layout(gtx, iconSize, MulAlpha(fg, 200))
+		o.Pop()
+	}
+	if e.trailIcon.icon != nil {
+		o := op.Offset(image.Pt(width-padX-iconSize, iconY)).Push(gtx.Ops)
+		e.trailIcon.layout(gtx, iconSize, MulAlpha(fg, 200))
+		o.Pop()
+	}
+
+	// Helper text and character counter below the field
+	helperH := 0
+	if e.helper != "" || e.showCounter || errText != "" {
+		col := MulAlpha(fg, 180)
+		helper := e.helper
+		if errText != "" {
+			// The error message replaces the helper text
+			col, helper = accent, errText
+		}
+		y := boxH + pb
+		call, dim := e.recordText(gtx, helper, smallSize, col)
+		o := op.Offset(image.Pt(padX, y)).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		o.Pop()
+		helperH = dim.Size.Y + pb
+		if e.showCounter {
+			call, dim := e.recordText(gtx, fmt.Sprintf("%d/%d", e.Editor.Len(), e.MaxLen), smallSize, col)
+			o := op.Offset(image.Pt(width-padX-dim.Size.X, y)).Push(gtx.Ops)
+			call.Add(gtx.Ops)
+			o.Pop()
+		}
+	}
+
+	e.handleHover(gtx, box)
+	e.layoutSuggestions(gtx, box)
+	return D{Size: image.Pt(width+ml+mr, top+boxH+helperH+mt+mb)}
+}
+
+// paintBorderGap paints a border like paintBorder, but leaves a gap in the top line
+// between x-positions gapStart and gapEnd. Used for the floating label.
+func paintBorderGap(gtx C, outline image.Rectangle, col color.NRGBA, width float32, rr int, gapStart, gapEnd int) {
+	if gapEnd <= gapStart {
+		paintBorder(gtx, outline, col, width, rr)
+		return
+	}
+	w := int(width) + 1
+	big := outline.Inset(-w)
+	// Paint the border three times, each time clipped to a part of the area outside the gap
+	areas := []image.Rectangle{
+		{Min: big.Min, Max: image.Pt(gapStart, big.Max.Y)},
+		{Min: image.Pt(gapEnd, big.Min.Y), Max: big.Max},
+		{Min: image.Pt(gapStart, outline.Min.Y+w), Max: image.Pt(gapEnd, big.Max.Y)},
+	}
+	for _, a := range areas {
+		cl := clip.Rect(a).Push(gtx.Ops)
+		paintBorder(gtx, outline, col, width, rr)
+		cl.Pop()
+	}
+}
+
+// LeadIcon is an option that puts an icon at the start of a text field
+func LeadIcon(ic *Icon) EditOption {
+	return func(e *EditDef) {
+		e.leadIcon.icon = ic
+	}
+}
+
+// TrailIcon is an option that puts an icon at the end of a text field.
+// The function is called when the icon is clicked. It can be nil.
+func TrailIcon(ic *Icon, onClick func()) EditOption {
+	return func(e *EditDef) {
+		e.trailIcon.icon = ic
+		e.trailIcon.onClick = onClick
+	}
+}
+
+// ClearBtn is an option that adds a trailing button clearing the text field
+func ClearBtn() EditOption {
+	return func(e *EditDef) {
+		e.trailIcon.icon = clearIcon
+		e.trailIcon.onClick = func() {
+			if e.ReadOnly {
+				return
+			}
+			e.SetText("")
+			// Update the variable at once, and call the handlers as for a change made by the user
+			e.touched = true
+			e.changePending = true
+			e.changeAt = time.Time{}
+			e.writeValue()
+			e.focusEditor = true
+		}
+	}
+}
+
+// PwdToggle is an option that adds a trailing button making a password visible/invisible.
+// The mask character is given as a rune option to Edit, and defaults to '*'
+func PwdToggle() EditOption {
+	return func(e *EditDef) {
+		e.trailIcon.icon = visibilityIcon
+		e.trailIcon.onClick = func() {
+			if e.Mask != 0 {
+				e.mask = e.Mask
+				e.Mask = 0
+				e.trailIcon.icon = visibilityOffIcon
+			} else {
+				e.Mask = e.mask
+				e.trailIcon.icon = visibilityIcon
+			}
+		}
+		if e.Mask == 0 {
+			e.Mask = '*'
+		}
+	}
+}
+
+// Prefix is an option to show a text in front of the value, like "$"
+func Prefix(s string) EditOption {
+	return func(e *EditDef) {
+		e.prefix = s
+	}
+}
+
+// Suffix is an option to show a text after the value, like "kg"
+func Suffix(s string) EditOption {
+	return func(e *EditDef) {
+		e.suffix = s
+	}
+}
+
+// Helper is an option to show a supporting text below the text field
+func Helper(s string) EditOption {
+	return func(e *EditDef) {
+		e.helper = s
+	}
+}
+
+// setError shows the text field in the error color, with the message
+// below it instead of the helper text. An empty message removes the error.
+func (e *EditDef) setError(msg string) {
+	e.errText = msg
+}
+
+// ErrorMsg is an option giving a variable with an error message, shown below the text field
+// in the error color. It is used for errors found by the program, like a name already in use.
+// An empty message removes the error. A message from Validate() is shown first.
+func ErrorMsg(msg *string) EditOption {
+	return func(e *EditDef) {
+		e.errMsg = msg
+	}
+}
+
+// errorText returns the error message to show
+func (e *EditDef) errorText() string {
+	if e.errText != "" || e.errMsg == nil {
+		return e.errText
+	}
+	GuiLock.RLock()
+	defer GuiLock.RUnlock()
+	return *e.errMsg
+}
+
+// Counter is an option that limits the number of characters, and
+// shows a character counter below the text field
+func Counter(maxLen int) EditOption {
+	return func(e *EditDef) {
+		e.MaxLen = maxLen
+		e.showCounter = true
+	}
+}
+
+func init() {
+	clearIcon, _ = NewIcon(icons.ContentClear)
+	visibilityIcon, _ = NewIcon(icons.ActionVisibility)
+	visibilityOffIcon, _ = NewIcon(icons.ActionVisibilityOff)
+}
@@ -0,0 +1,554 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+var clockIcon *Icon
+
+// Dial modes, selecting what the clock dial is used for
+const (
+	dialHours = iota
+	dialMinutes
+	dialSeconds
+)
+
+// TimePickerDef is a text field for a time of day or a duration, with a clock dial popup.
+// It embeds an EditDef, so all the options for edits can be used.
+type TimePickerDef struct {
+	EditDef
+	timeValue *time.Time
+	duration  *time.Duration
+	clock12   bool
+	seconds   bool
+	open      bool
+	hasFocus  bool
+	// mode is dialHours, dialMinutes or dialSeconds
+	mode     int
+	hour     int
+	minute   int
+	second   int
+	dragging bool
+	// center and radius of the dial, from the last frame
+	center     f32.Point
+	radius     float32
+	modeClicks [3]gesture.Click
+	amPm       [2]gesture.Click
+}
+
+// TimeOption is options specific to time pickers
+type TimeOption func(t *TimePickerDef)
+
+func (o TimeOption) apply(cfg interface{}) {
+	if t, ok := cfg.(*TimePickerDef); ok {
+		o(t)
+	}
+}
+
+// Clock12 is an option showing the time with AM/PM instead of 24 hours
+func Clock12() TimeOption {
+	return func(t *TimePickerDef) {
+		t.clock12 = true
+	}
+}
+
+// Seconds is an option that includes seconds in the time
+func Seconds() TimeOption {
+	return func(t *TimePickerDef) {
+		t.seconds = true
+	}
+}
+
+// TimePicker returns a text field for a time, with a button opening a clock dial.
+// The value can be a *time.Time, where only the time of day is changed, or a *time.Duration.
+// Durations can be typed with more than 24 hours, but always use the 24-hour clock.
+func TimePicker[V *time.Time | *time.Duration](th *Theme, value V, options ...any) layout.Widget {
+	switch v := any(value).(type) {
+	case *time.Time:
+		return newTimePicker(th, v, nil, options).Layout
+	case *time.Duration:
+		return newTimePicker(th, nil, v, options).Layout
+	}
+	return nil
+}
+
+func newTimePicker(th *Theme, value *time.Time, duration *time.Duration, options []any) *TimePickerDef {
+	t := &TimePickerDef{EditDef: DefaultEditDef(th), timeValue: value, duration: duration}
+	t.style = OutlinedField
+	i := 0
+	t.DpNo = &i
+	for _, option := range options {
+		if v, ok := option.(TimeOption); ok {
+			v(t)
+		} else if v, ok := option.(UIRole); ok {
+			t.role = v
+		} else if v, ok := option.(FieldStyle); ok {
+			t.style = v
+		} else if v, ok := option.(Option); ok {
+			v.apply(&t.EditDef)
+		}
+	}
+	if t.duration != nil {
+		t.clock12 = false
+	}
+	if t.hint == "" {
+		t.hint = "hh:mm"
+		if t.seconds {
+			t.hint += ":ss"
+		}
+		if t.clock12 {
+			t.hint += " am/pm"
+		}
+	}
+	t.trailIcon.icon = clockIcon
+	t.trailIcon.onClick = func() {
+		if !t.open {
+			t.openDial()
+		} else {
+			t.open = false
+		}
+		t.focusEditor = true
+	}
+	// Enter will update the variable and close the dial
+	onSubmit := t.onSubmit
+	t.onSubmit = func(text string) {
+		t.commit()
+		t.open = false
+		if onSubmit != nil {
+			onSubmit(t.Text())
+		}
+	}
+	t.Submit = true
+	return t
+}
+
+// get returns the bound value as hours, minutes and seconds. It is not ok for a zero time.
+func (t *TimePickerDef) get() (h, m, s int, ok bool) {
+	GuiLock.RLock()
+	defer GuiLock.RUnlock()
+	if t.timeValue != nil && !t.timeValue.IsZero() {
+		return t.timeValue.Hour(), t.timeValue.Minute(), t.timeValue.Second(), true
+	}
+	if t.duration != nil {
+		d := t.duration.Round(time.Second)
+		return int(d / time.Hour), int(d/time.Minute) % 60, int(d/time.Second) % 60, true
+	}
+	return 0, 0, 0, false
+}
+
+// set updates the bound value, and calls the Do() handler if it changed.
+func (t *TimePickerDef) set(h, m, s int) {
+	changed := false
+	GuiLock.Lock()
+	if t.timeValue != nil {
+		old := *t.timeValue
+		day := old
+		if day.IsZero() {
+			// A time without a date is today
+			day = dayOf(time.Now())
+		}
+		*t.timeValue = time.Date(day.Year(), day.Month(), day.Day(), h, m, s, 0, day.Location())
+		changed = !t.timeValue.Equal(old)
+		t.record(t.timeValue, old)
+	} else if t.duration != nil {
+		d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+		changed = *t.duration != d
+		old := *t.duration
+		*t.duration = d
+		t.record(t.duration, old)
+	}
+	GuiLock.Unlock()
+	t.SetText(t.format(h, m, s))
+	n := t.Len()
+	t.SetCaret(n, n)
+	if changed && t.onUserChange != nil {
+		t.onUserChange()
+	}
+}
+
+// format returns the time as text, like "14:05", "2:05 PM" or "14:05:30"
+func (t *TimePickerDef) format(h, m, s int) string {
+	suffix := ""
+	if t.clock12 {
+		suffix = " AM"
+		if h >= 12 {
+			suffix = " PM"
+		}
+		h = (h+11)%12 + 1
+	}
+	str := fmt.Sprintf("%02d:%02d", h, m)
+	if t.clock12 {
+		str = fmt.Sprintf("%d:%02d", h, m)
+	}
+	if t.seconds {
+		str += fmt.Sprintf(":%02d", s)
+	}
+	return str + suffix
+}
+
+// parse converts a text like "14:05", "2:05pm" or "14:05:30" to hours, minutes and seconds.
+// An AM/PM suffix is accepted also when using the 24-hour clock.
+func (t *TimePickerDef) parse(str string) (h, m, s int, ok bool) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	am, pm := strings.HasSuffix(str, "am"), strings.HasSuffix(str, "pm")
+	if am || pm {
+		str = strings.TrimSpace(str[:len(str)-2])
+	}
+	parts := strings.Split(str, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, 0, false
+	}
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return 0, 0, 0, false
+		}
+		n[i] = v
+	}
+	h, m, s = n[0], n[1], n[2]
+	if am || pm {
+		if t.duration != nil || h < 1 || h > 12 {
+			return 0, 0, 0, false
+		}
+		h = h % 12
+		if pm {
+			h += 12
+		}
+	}
+	if m > 59 || s > 59 || t.duration == nil && h > 23 {
+		return 0, 0, 0, false
+	}
+	return h, m, s, true
+}
+
+// commit updates the variable from the edited text. Invalid text is replaced by the old value.
+func (t *TimePickerDef) commit() {
+	if strings.TrimSpace(t.Text()) == "" && t.timeValue != nil {
+		GuiLock.Lock()
+		old := *t.timeValue
+		changed := !old.IsZero()
+		*t.timeValue = time.Time{}
+		t.record(t.timeValue, old)
+		GuiLock.Unlock()
+		if changed && t.onUserChange != nil {
+			t.onUserChange()
+		}
+		t.SetText("")
+	} else if h, m, s, ok := t.parse(t.Text()); ok {
+		t.set(h, m, s)
+	} else {
+		t.SetText(t.valueText())
+	}
+}
+
+// valueText returns the bound value as text, empty for a zero time
+func (t *TimePickerDef) valueText() string {
+	if h, m, s, ok := t.get(); ok {
+		return t.format(h, m, s)
+	}
+	return ""
+}
+
+// openDial shows the clock dial, starting with the hours
+func (t *TimePickerDef) openDial() {
+	h, m, s, ok := t.parse(t.Text())
+	if !ok {
+		h, m, s, _ = t.get()
+	}
+	t.hour, t.minute, t.second = h, m, s
+	t.mode = dialHours
+	t.open = true
+}
+
+// handleDial processes pointer events on the dial, and clicks in the header
+func (t *TimePickerDef) handleDial(gtx C) {
+	for i := range t.modeClicks {
+		for {
+			ev, ok := t.modeClicks[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind == gesture.KindClick {
+				t.mode = i
+			}
+		}
+	}
+	for i := range t.amPm {
+		for {
+			ev, ok := t.amPm[i].Update(gtx.Source)
+			if !ok {
+				break
+			}
+			if ev.Kind == gesture.KindClick && t.hour/12 != i {
+				t.hour = t.hour%12 + 12*i
+				t.set(t.hour, t.minute, t.second)
+			}
+		}
+	}
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: &t.dragging, Kinds: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel})
+		if !ok {
+			break
+		}
+		e, ok := ev.(pointer.Event)
+		if !ok || !t.open {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press:
+			t.dragging = true
+			t.dialAt(e.Position)
+		case pointer.Drag:
+			if t.dragging {
+				t.dialAt(e.Position)
+			}
+		case pointer.Release:
+			if !t.dragging {
+				break
+			}
+			t.dragging = false
+			t.dialAt(e.Position)
+			t.set(t.hour, t.minute, t.second)
+			// Go on to the next part of the time, or close the dial when finished
+			if t.mode == dialHours || t.mode == dialMinutes && t.seconds {
+				t.mode++
+			} else {
+				t.open = false
+			}
+		case pointer.Cancel:
+			t.dragging = false
+		}
+	}
+	if !t.open {
+		return
+	}
+	for {
+		ev, ok := gtx.Event(key.Filter{Focus: &t.Editor, Name: key.NameEscape})
+		if !ok {
+			break
+		}
+		if ke, ok := ev.(key.Event); ok && ke.State == key.Press {
+			t.open = false
+		}
+	}
+}
+
+// dialAt sets the hour, minute or second from a pointer position on the dial
+func (t *TimePickerDef) dialAt(pos f32.Point) {
+	dx, dy := float64(pos.X-t.center.X), float64(pos.Y-t.center.Y)
+	a := math.Atan2(dx, -dy)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	if t.mode == dialHours {
+		// Durations can be more than 24 hours, and the days are kept
+		days := t.hour / 24 * 24
+		k := int(math.Round(a/(2*math.Pi)*12)) % 12
+		if t.clock12 {
+			t.hour = k + 12*(t.hour/12)
+		} else if math.Hypot(dx, dy) < float64(t.radius)*0.62 {
+			// The inner ring has the hours 12 to 23
+			t.hour = days + k + 12
+		} else {
+			t.hour = days + k
+		}
+		return
+	}
+	v := int(math.Round(a/(2*math.Pi)*60)) % 60
+	if t.mode == dialMinutes {
+		t.minute = v
+	} else {
+		t.second = v
+	}
+}
+
+func (t *TimePickerDef) Layout(gtx C) D {
+	focused := gtx.Focused(&t.Editor)
+	if !focused {
+		if t.hasFocus {
+			t.commit()
+		}
+		t.open = false
+		if s := t.valueText(); s != t.Text() {
+			t.SetText(s)
+		}
+	} else if t.style == PlainField && !t.hasFocus {
+		// Plain fields have no clock button, so the dial opens when focused
+		t.openDial()
+	}
+	t.hasFocus = focused
+	t.handleDial(gtx)
+	t.setError("")
+	if focused && strings.TrimSpace(t.Text()) != "" {
+		if _, _, _, ok := t.parse(t.Text()); !ok {
+			t.setError("Invalid time")
+		}
+	}
+	dims := t.EditDef.Layout(gtx)
+	if t.open {
+		mt, mb, ml, _ := ScaleInset(gtx, t.margin)
+		layoutPopup(gtx, t.th, &t.open, image.Pt(ml, dims.Size.Y-mb-mt/2), t.layoutDial)
+	}
+	return dims
+}
+
+// layoutDial draws the header with the selected time, and the clock dial
+func (t *TimePickerDef) layoutDial(gtx C) D {
+	th := t.th
+	textSize := th.TextSize * unit.Sp(t.FontScale)
+	cell := gtx.Sp(textSize) * 5 / 2
+	pad := cell / 4
+	width := 7*cell + 2*pad
+	// Header with boxes for hours, minutes and seconds. They are clicked to select dial mode.
+	boxW, boxH := cell*3/2, cell*3/2
+	h := t.hour
+	if t.clock12 {
+		h = (h+11)%12 + 1
+	}
+	values := []int{h, t.minute, t.second}
+	n := 2
+	if t.seconds {
+		n = 3
+	}
+	x := pad
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			t.centerText(gtx, ":", image.Pt(x, pad), cell/2, boxH, th.Fg[SurfaceContainerHigh], 2)
+			x += cell / 2
+		}
+		bg, fg := th.Bg[SurfaceContainerHighest], th.Fg[SurfaceContainerHighest]
+		if t.mode == i {
+			bg, fg = th.Bg[PrimaryContainer], th.Fg[PrimaryContainer]
+		}
+		r := image.Rect(x, pad, x+boxW, pad+boxH)
+		paint.FillShape(gtx.Ops, bg, clip.UniformRRect(r, Px(gtx, unit.Dp(8))).Op(gtx.Ops))
+		t.centerText(gtx, fmt.Sprintf("%02d", values[i]), r.Min, boxW, boxH, fg, 2)
+		cl := clip.Rect(r).Push(gtx.Ops)
+		t.modeClicks[i].Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		cl.Pop()
+		x += boxW
+	}
+	if t.clock12 {
+		// AM and PM selectors to the right
+		x = width - pad - cell*3/2
+		for i, s := range []string{"AM", "PM"} {
+			r := image.Rect(x, pad+i*boxH/2, x+cell*3/2, pad+(i+1)*boxH/2)
+			fg := th.Fg[SurfaceContainerHigh]
+			if t.hour/12 == i {
+				paint.FillShape(gtx.Ops, th.Bg[TertiaryContainer], clip.Rect(r).Op())
+				fg = th.Fg[TertiaryContainer]
+			}
+			t.centerText(gtx, s, r.Min, r.Dx(), r.Dy(), fg, 1)
+			cl := clip.Rect(r).Push(gtx.Ops)
+			t.amPm[i].Add(gtx.Ops)
+			pointer.CursorPointer.Add(gtx.Ops)
+			cl.Pop()
+		}
+		r := image.Rect(x, pad, x+cell*3/2, pad+boxH)
+		paintBorder(gtx, r, th.Fg[Outline], float32(Px(gtx, unit.Dp(1))), 0)
+	}
+	// The dial itself
+	y := pad*2 + boxH
+	size := 7 * cell
+	r := float32(size) / 2
+	t.center = f32.Pt(float32(pad)+r, float32(y)+r)
+	t.radius = r
+	dial := image.Rect(pad, y, pad+size, y+size)
+	paint.FillShape(gtx.Ops, th.Bg[SurfaceContainerHighest], clip.Ellipse(dial).Op(gtx.Ops))
+
+	// Find the selected value and the position of the hand
+	sel, count := t.minute, 60
+	if t.mode == dialSeconds {
+		sel = t.second
+	}
+	ring := r - float32(cell)/2
+	if t.mode == dialHours {
+		sel, count = t.hour%12, 12
+		if !t.clock12 && t.hour%24 >= 12 {
+			ring = r - float32(cell)*3/2
+		}
+	}
+	pos := dialPoint(t.center, ring, float64(sel)/float64(count))
+	hand := clip.Path{}
+	hand.Begin(gtx.Ops)
+	hand.MoveTo(t.center)
+	hand.LineTo(pos)
+	paint.FillShape(gtx.Ops, th.Bg[Primary], clip.Stroke{Path: hand.End(), Width: float32(Px(gtx, unit.Dp(2)))}.Op())
+	dot := float32(Px(gtx, unit.Dp(3)))
+	paint.FillShape(gtx.Ops, th.Bg[Primary], clip.Ellipse{Min: t.center.Sub(f32.Pt(dot, dot)).Round(), Max: t.center.Add(f32.Pt(dot, dot)).Round()}.Op(gtx.Ops))
+	knob := f32.Pt(float32(cell)/2, float32(cell)/2)
+	paint.FillShape(gtx.Ops, th.Bg[Primary], clip.Ellipse{Min: pos.Sub(knob).Round(), Max: pos.Add(knob).Round()}.Op(gtx.Ops))
+
+	// Numbers around the dial
+	fg := th.Fg[SurfaceContainerHighest]
+	for i := 0; i < 12; i++ {
+		v := i * 5
+		if t.mode == dialHours {
+			v = i
+			if t.clock12 && i == 0 {
+				v = 12
+			}
+		}
+		col := fg
+		if t.mode != dialHours && v == sel || t.mode == dialHours && i == sel && (t.clock12 || t.hour%24 < 12) {
+			col = th.Fg[Primary]
+		}
+		p := dialPoint(t.center, r-float32(cell)/2, float64(i)/12).Round()
+		t.centerText(gtx, fmt.Sprintf("%02d", v), p.Sub(image.Pt(cell/2, cell/2)), cell, cell, col, 1)
+		if t.mode == dialHours && !t.clock12 {
+			// Inner ring for 24 hour clock
+			col = MulAlpha(fg, 200)
+			if t.hour%24 == i+12 {
+				col = th.Fg[Primary]
+			}
+			p := dialPoint(t.center, r-float32(cell)*3/2, float64(i)/12).Round()
+			t.centerText(gtx, fmt.Sprintf("%02d", i+12), p.Sub(image.Pt(cell/2, cell/2)), cell, cell, col, 0.8)
+		}
+	}
+	// Pointer events for selecting and dragging
+	cl := clip.Ellipse(dial).Push(gtx.Ops)
+	event.Op(gtx.Ops, &t.dragging)
+	pointer.CursorPointer.Add(gtx.Ops)
+	cl.Pop()
+	return D{Size: image.Pt(width, y+size+pad)}
+}
+
+// dialPoint returns the point at the given fraction of a full turn, clockwise from the top
+func dialPoint(center f32.Point, r float32, f float64) f32.Point {
+	a := 2 * math.Pi * f
+	return f32.Pt(center.X+r*float32(math.Sin(a)), center.Y-r*float32(math.Cos(a)))
+}
+
+// centerText draws the text scaled by the given factor, and centered in the box at pos with size w,h
+func (t *TimePickerDef) centerText(gtx C, s string, pos image.Point, w, h int, col color.NRGBA, scale float32) {
+	call, dims := t.recordText(gtx, s, t.th.TextSize*unit.Sp(t.FontScale*float64(scale)), col)
+	defer op.Offset(pos.Add(image.Pt((w-dims.Size.X)/2, (h-dims.Size.Y)/2))).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+func init() {
+	clockIcon, _ = NewIcon(icons.DeviceAccessTime)
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// TokenKind is the kind of a token, used to select the color of the text.
+type TokenKind uint8
+
+const (
+	// TokText is plain text
+	TokText TokenKind = iota
+	// TokKeyword is a language keyword
+	TokKeyword
+	// TokType is a type name
+	TokType
+	// TokString is a string or character literal
+	TokString
+	// TokNumber is a numeric literal
+	TokNumber
+	// TokLiteral is a named constant like true, false and nil
+	TokLiteral
+	// TokComment is a comment
+	TokComment
+	// TokKey is a key in a JSON object or YAML mapping
+	TokKey
+)
+
+// Token is a part of the text to be highlighted. Start and End are byte offsets.
+type Token struct {
+	Start, End int
+	Kind       TokenKind
+}
+
+// Tokenizer splits a text into tokens for syntax highlighting.
+// Text that is not covered by a token is drawn as TokText.
+type Tokenizer func(text string) []Token
+
+var goTypes = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true, "error": true, "float32": true,
+	"float64": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"uintptr": true, "any": true,
+}
+
+// GoTokens is the tokenizer for Go source code.
+func GoTokens(text string) []Token {
+	var tokens []Token
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(text))
+	// Errors are ignored, the text is often incomplete while it is edited
+	s.Init(file, []byte(text), nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		kind := TokText
+		switch {
+		case tok.IsKeyword():
+			kind, lit = TokKeyword, tok.String()
+		case tok == token.STRING || tok == token.CHAR:
+			kind = TokString
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			kind = TokNumber
+		case tok == token.COMMENT:
+			kind = TokComment
+		case tok == token.IDENT && goTypes[lit]:
+			kind = TokType
+		case tok == token.IDENT && (lit == "true" || lit == "false" || lit == "nil" || lit == "iota"):
+			kind = TokLiteral
+		}
+		if kind != TokText {
+			start := file.Offset(pos)
+			tokens = append(tokens, Token{Start: start, End: start + len(lit), Kind: kind})
+		}
+	}
+	return tokens
+}
+
+// JSONTokens is the tokenizer for JSON.
+func JSONTokens(text string) []Token {
+	var tokens []Token
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := stringEnd(text, i)
+			kind := TokString
+			if strings.HasPrefix(strings.TrimLeft(text[end:], " \t\r\n"), ":") {
+				kind = TokKey
+			}
+			tokens = append(tokens, Token{Start: i, End: end, Kind: kind})
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789.eE+-", text[end]) >= 0 {
+				end++
+			}
+			tokens = append(tokens, Token{Start: i, End: end, Kind: TokNumber})
+			i = end
+		case c >= 'a' && c <= 'z':
+			end := i + 1
+			for end < len(text) && text[end] >= 'a' && text[end] <= 'z' {
+				end++
+			}
+			if w := text[i:end]; w == "true" || w == "false" || w == "null" {
+				tokens = append(tokens, Token{Start: i, End: end, Kind: TokLiteral})
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// stringEnd returns the offset after the string starting with a quote at text[i].
+// Unterminated strings end at the end of the line.
+func stringEnd(text string, i int) int {
+	q := text[i]
+	for j := i + 1; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			if q == '"' {
+				j++
+			}
+		case q:
+			return j + 1
+		case '\n':
+			return j
+		}
+	}
+	return len(text)
+}
+
+var (
+	yamlKey    = regexp.MustCompile(`^(\s*(?:-\s+)?)([^\s#'"{}\[\],&*!|>%@` + "`" + `][^#:]*?|"[^"]*"|'[^']*'):(\s|$)`)
+	yamlNumber = regexp.MustCompile(`^[-+]?(\d[\d_]*(\.\d*)?([eE][-+]?\d+)?|\.\d+|0x[0-9a-fA-F]+|\.inf|\.nan)$`)
+)
+
+// YAMLTokens is the tokenizer for YAML.
+func YAMLTokens(text string) []Token {
+	var tokens []Token
+	start := 0
+	for start <= len(text) {
+		end := strings.IndexByte(text[start:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		tokens = yamlLine(tokens, text[start:end], start)
+		start = end + 1
+	}
+	return tokens
+}
+
+// yamlLine adds the tokens found in one line, starting at offset ofs in the text.
+func yamlLine(tokens []Token, line string, ofs int) []Token {
+	t := strings.TrimSpace(line)
+	if t == "---" || t == "..." {
+		return append(tokens, Token{Start: ofs, End: ofs + len(line), Kind: TokKeyword})
+	}
+	// Find the comment, which must be at the start or after a space, and not in a string
+	code := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' || line[i] == '\'' {
+			i = stringEnd(line, i) - 1
+		} else if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			code = i
+			break
+		}
+	}
+	i := 0
+	if m := yamlKey.FindStringSubmatchIndex(line[:code]); m != nil {
+		tokens = append(tokens, Token{Start: ofs + m[4], End: ofs + m[5], Kind: TokKey})
+		i = m[5] + 1
+	} else {
+		// Skip list markers
+		for i < code && (line[i] == ' ' || line[i] == '-') {
+			i++
+		}
+	}
+	value := strings.TrimSpace(line[i:code])
+	if value != "" {
+		s := ofs + i + strings.Index(line[i:code], value)
+		kind := TokText
+		switch {
+		case value[0] == '"' || value[0] == '\'':
+			kind = TokString
+		case value[0] == '&' || value[0] == '*' || value[0] == '!':
+			kind = TokType
+		case yamlNumber.MatchString(value):
+			kind = TokNumber
+		default:
+			switch strings.ToLower(value) {
+			case "true", "false", "yes", "no", "on", "off", "null", "~":
+				kind = TokLiteral
+			}
+		}
+		if kind != TokText {
+			tokens = append(tokens, Token{Start: s, End: s + len(value), Kind: kind})
+		}
+	}
+	if code < len(line) {
+		tokens = append(tokens, Token{Start: ofs + code, End: ofs + len(line), Kind: TokComment})
+	}
+	return tokens
+}
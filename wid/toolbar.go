@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// ToolDef is an item in a toolbar. It is a button, a toggle button, a separator or a dropdown.
+type ToolDef struct {
+	w Wid
+	// item is used in the more menu when the tool does not fit in the toolbar
+	item      *MenuDef
+	separator bool
+}
+
+// ToolbarDef is a row of tools. The tools that do not fit are moved to a menu opened
+// by a "more" button at the end of the toolbar.
+type ToolbarDef struct {
+	Base
+	tools    []*ToolDef
+	more     MenuBarDef
+	moreMenu *MenuDef
+	moreBtn  *ButtonDef
+	// fit is the number of tools shown in the toolbar the last frame
+	fit int
+	// right aligns the tools to the right end, as in top app bars
+	right bool
+}
+
+var (
+	moreIcon    *Icon
+	transparent color.NRGBA
+)
+
+// Toolbar returns a row of tools on a SurfaceContainer background. When the window is
+// too narrow, the last tools are found in the menu of a more button instead.
+func Toolbar(th *Theme, tools ...*ToolDef) layout.Widget {
+	return newToolbar(th, tools).Layout
+}
+
+func newToolbar(th *Theme, tools []*ToolDef) *ToolbarDef {
+	t := &ToolbarDef{
+		Base: Base{
+			th:        th,
+			role:      SurfaceContainer,
+			padding:   layout.Inset{Left: 4, Right: 4, Top: 4, Bottom: 4},
+			Font:      &th.DefaultFont,
+			FontScale: 1.0,
+		},
+		tools: tools,
+		fit:   -1,
+	}
+	t.moreMenu = Menu(th, "More")
+	t.more = MenuBarDef{
+		Base:  t.Base,
+		menus: []*MenuDef{t.moreMenu},
+		hl:    -1,
+	}
+	t.moreBtn = toolButton(th, moreIcon, "More", []Option{Do(func() {
+		if len(t.more.open) == 0 {
+			t.more.openTop(0, false)
+		}
+	})})
+	return t
+}
+
+// toolButton is a round icon button without a container, with the foreground color of the toolbar
+func toolButton(th *Theme, icon *Icon, label string, options []Option) *ButtonDef {
+	options = append([]Option{Role(SurfaceContainer), Bg(&transparent), BtnIcon(icon), W(0), RR(99999), Hint(label)}, options...)
+	return aButton(Round, th, "", options...)
+}
+
+// ToolButton returns an icon button for a toolbar. The label is shown as a tooltip, and is the
+// text of the item in the more menu. The Do() option gives the function called when it is clicked.
+func ToolButton(th *Theme, icon *Icon, label string, options ...Option) *ToolDef {
+	b := toolButton(th, icon, label, options)
+	item := MenuItem(th, label)
+	item.onUserChange, item.disabler = b.onUserChange, b.disabler
+	return &ToolDef{w: b.Layout, item: item}
+}
+
+// ToolToggle returns an icon button that toggles the value. It has a SecondaryContainer background
+// when the value is true, and has a check mark in the more menu.
+func ToolToggle(th *Theme, icon *Icon, label string, value *bool, options ...Option) *ToolDef {
+	b := toolButton(th, icon, label, options)
+	do := b.onUserChange
+	toggle := func() {
+		GuiLock.Lock()
+		old := *value
+		*value = !old
+		b.record(value, old)
+		GuiLock.Unlock()
+		if do != nil {
+			do()
+		}
+	}
+	b.onUserChange = toggle
+	item := MenuItem(th, label)
+	item.onUserChange, item.disabler = toggle, b.disabler
+	item.checked = func() bool {
+		GuiLock.RLock()
+		defer GuiLock.RUnlock()
+		return *value
+	}
+	w := func(gtx C) D {
+		b.role, b.bgColor = SurfaceContainer, &transparent
+		if item.checked() {
+			b.role, b.bgColor = SecondaryContainer, nil
+		}
+		return b.Layout(gtx)
+	}
+	return &ToolDef{w: w, item: item}
+}
+
+// ToolSeparator returns a vertical line between groups of tools
+func ToolSeparator(th *Theme) *ToolDef {
+	w := func(gtx C) D {
+		w, h := Px(gtx, unit.Dp(17)), Px(gtx, unit.Dp(24))
+		dw := Max(1, Px(gtx, unit.Dp(1)))
+		paint.FillShape(gtx.Ops, th.Bg[OutlineVariant], clip.Rect{Min: image.Pt((w-dw)/2, 0), Max: image.Pt((w+dw)/2, h)}.Op())
+		return D{Size: image.Pt(w, h)}
+	}
+	return &ToolDef{w: w, separator: true}
+}
+
+// ToolDropDown returns a dropdown for a toolbar, with a default width of 150dp.
+// In the more menu, it is a submenu with the label as text.
+func ToolDropDown(th *Theme, index *int, items []string, label string, options ...Option) *ToolDef {
+	// The Do() and En() options are also used by the menu items
+	var base Base
+	for _, option := range options {
+		if o, ok := option.(BaseOption); ok {
+			o(&base)
+		}
+	}
+	sub := make([]*MenuDef, len(items))
+	for i := range items {
+		i := i
+		sub[i] = MenuItem(th, items[i])
+		sub[i].disabler = base.disabler
+		sub[i].onUserChange = func() {
+			GuiLock.Lock()
+			old := *index
+			*index = i
+			if old != i {
+				base.record(index, old)
+			}
+			GuiLock.Unlock()
+			if old != i && base.onUserChange != nil {
+				base.onUserChange()
+			}
+		}
+		sub[i].checked = func() bool {
+			GuiLock.RLock()
+			defer GuiLock.RUnlock()
+			return *index == i
+		}
+	}
+	options = append([]Option{W(150), Hint(label)}, options...)
+	return &ToolDef{w: DropDown(th, index, items, options...), item: Menu(th, label, sub...)}
+}
+
+// Layout draws the tools that fit, and the more button when some do not
+func (t *ToolbarDef) Layout(gtx C) D {
+	pt, pb, pl, pr := ScaleInset(gtx, t.padding)
+	width := gtx.Constraints.Max.X
+	c := gtx
+	c.Constraints = layout.Constraints{Max: image.Pt(width, gtx.Constraints.Max.Y)}
+	// The tools are recorded to find their sizes
+	calls := make([]op.CallOp, len(t.tools))
+	sizes := make([]image.Point, len(t.tools))
+	total := pl + pr
+	for i, tool := range t.tools {
+		m := op.Record(gtx.Ops)
+		sizes[i] = tool.w(c).Size
+		calls[i] = m.Stop()
+		total += sizes[i].X
+	}
+	m := op.Record(gtx.Ops)
+	moreSize := t.moreBtn.Layout(c).Size
+	moreCall := m.Stop()
+
+	fit := len(t.tools)
+	if total > width {
+		x := pl + pr + moreSize.X
+		fit = 0
+		for fit < len(t.tools) && x+sizes[fit].X <= width {
+			x += sizes[fit].X
+			fit++
+		}
+	}
+	if fit != t.fit {
+		// The more menu has the tools that do not fit, without separators at the ends
+		t.fit = fit
+		t.more.open, t.more.hl = nil, -1
+		t.moreMenu.items = t.moreMenu.items[:0]
+		for _, tool := range t.tools[fit:] {
+			if tool.separator && len(t.moreMenu.items) > 0 {
+				t.moreMenu.items = append(t.moreMenu.items, MenuSeparator(t.th))
+			} else if tool.item != nil {
+				t.moreMenu.items = append(t.moreMenu.items, tool.item)
+			}
+		}
+		if n := len(t.moreMenu.items); n > 0 && t.moreMenu.items[n-1].separator {
+			t.moreMenu.items = t.moreMenu.items[:n-1]
+		}
+	}
+	shown := fit
+	if shown > 0 && shown < len(t.tools) && t.tools[shown-1].separator {
+		shown--
+	}
+	height := 0
+	for _, sz := range sizes[:shown] {
+		height = Max(height, sz.Y)
+	}
+	if fit < len(t.tools) {
+		height = Max(height, moreSize.Y)
+	}
+	height += pt + pb
+	paint.FillShape(gtx.Ops, t.Bg(), clip.Rect{Max: image.Pt(width, height)}.Op())
+	x := pl
+	if t.right {
+		x = width - pr
+		for _, sz := range sizes[:shown] {
+			x -= sz.X
+		}
+		if fit < len(t.tools) {
+			x -= moreSize.X
+		}
+	}
+	for i := 0; i < shown; i++ {
+		o := op.Offset(image.Pt(x, pt+(height-pt-pb-sizes[i].Y)/2)).Push(gtx.Ops)
+		calls[i].Add(gtx.Ops)
+		o.Pop()
+		x += sizes[i].X
+	}
+	if fit < len(t.tools) {
+		p := image.Pt(width-pr-moreSize.X, pt+(height-pt-pb-moreSize.Y)/2)
+		o := op.Offset(p).Push(gtx.Ops)
+		moreCall.Add(gtx.Ops)
+		o.Pop()
+		t.more.popup(gtx, image.Rectangle{Min: p, Max: p.Add(moreSize)}, width)
+	}
+	return D{Size: image.Pt(width, height)}
+}
+
+func init() {
+	moreIcon, _ = NewIcon(icons.NavigationMoreVert)
+}
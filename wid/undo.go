@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package wid
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"gioui.org/io/key"
+)
+
+// undoGroup is the time within which changes are merged into one undo step.
+// Typing a word or dragging a slider will then be undone in one step.
+const undoGroup = time.Second
+
+// History is a form level undo stack. When it is activated by TrackChanges,
+// every change made to a bound variable by the widgets is recorded,
+// with its old and new value.
+type History struct {
+	mu    sync.Mutex
+	undo  []valueChange
+	redo  []valueChange
+	limit int
+	at    time.Time
+}
+
+// valueChange is a change to the variable pointed to by ptr. A change to a part
+// of the variable, like one byte in a slice, is given by functions undoing and redoing it.
+type valueChange struct {
+	ptr        any
+	old, new   any
+	undo, redo func()
+}
+
+var history *History
+
+// NewHistory returns an undo stack keeping at most limit changes.
+// A limit of 0 gives an unlimited stack.
+func NewHistory(limit int) *History {
+	return &History{limit: limit}
+}
+
+// TrackChanges will record all value changes made by the widgets in the given history.
+// Use nil to stop recording.
+func TrackChanges(h *History) {
+	history = h
+}
+
+// valueOf returns a copy of the value that ptr points to.
+func valueOf(ptr any) any {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
+
+// setValue writes v to the variable that ptr points to.
+func setValue(ptr any, v any) {
+	reflect.ValueOf(ptr).Elem().Set(reflect.ValueOf(v))
+}
+
+// recordChange is called by the widgets after they have written a new value
+// to ptr. The old value is the one before the change.
+func recordChange(ptr any, old any) {
+	h := history
+	if h == nil || valueOf(ptr) == nil || isDraft(ptr) {
+		return
+	}
+	h.record(ptr, old, valueOf(ptr), time.Now())
+}
+
+// recordEdit is called by the widgets after they have changed a part of the variable
+// pointed to by ptr. The functions undo and redo the change, and are called with GuiLock held.
+func recordEdit(ptr any, undo, redo func()) {
+	h := history
+	if h == nil || isDraft(ptr) {
+		return
+	}
+	h.recordEdit(ptr, undo, redo, time.Now())
+}
+
+// record is used by the widgets instead of recordChange, so that changes
+// to variables bound by the Untracked() option are skipped.
+func (wid *Base) record(ptr any, old any) {
+	if !wid.untracked {
+		recordChange(ptr, old)
+	}
+}
+
+// recordEdit is like record, for a change to a part of the variable
+func (wid *Base) recordEdit(ptr any, undo, redo func()) {
+	if !wid.untracked {
+		recordEdit(ptr, undo, redo)
+	}
+}
+
+// Untracked is an option for widgets bound to variables that are not form data,
+// like a search text. Changes made to them are not recorded by TrackChanges.
+func Untracked() BaseOption {
+	return func(w BaseIf) {
+		w.setUntracked()
+	}
+}
+
+func (h *History) record(ptr, old, new any, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := len(h.undo)
+	if n > 0 && h.undo[n-1].ptr == ptr && h.undo[n-1].undo == nil && now.Sub(h.at) < undoGroup {
+		// Merge with the previous change to the same variable
+		h.undo[n-1].new = new
+		if reflect.DeepEqual(h.undo[n-1].old, new) {
+			h.undo = h.undo[:n-1]
+		}
+	} else if !reflect.DeepEqual(old, new) {
+		h.undo = append(h.undo, valueChange{ptr: ptr, old: old, new: new})
+		if h.limit > 0 && len(h.undo) > h.limit {
+			h.undo = h.undo[len(h.undo)-h.limit:]
+		}
+	} else {
+		return
+	}
+	h.redo = h.redo[:0]
+	h.at = now
+}
+
+func (h *History) recordEdit(ptr any, undo, redo func(), now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := len(h.undo)
+	if n > 0 && h.undo[n-1].ptr == ptr && h.undo[n-1].undo != nil && now.Sub(h.at) < undoGroup {
+		// Merge with the previous edit of the same variable
+		c := &h.undo[n-1]
+		prevUndo, prevRedo := c.undo, c.redo
+		c.undo = func() { undo(); prevUndo() }
+		c.redo = func() { prevRedo(); redo() }
+	} else {
+		h.undo = append(h.undo, valueChange{ptr: ptr, undo: undo, redo: redo})
+		if h.limit > 0 && len(h.undo) > h.limit {
+			h.undo = h.undo[len(h.undo)-h.limit:]
+		}
+	}
+	h.redo = h.redo[:0]
+	h.at = now
+}
+
+// apply sets the variable to the old or the new value of the change
+func (c valueChange) apply(undo bool) {
+	switch {
+	case undo && c.undo != nil:
+		c.undo()
+	case c.redo != nil:
+		c.redo()
+	case undo:
+		setValue(c.ptr, c.old)
+	default:
+		setValue(c.ptr, c.new)
+	}
+}
+
+// Undo restores the variable changed last to its old value.
+// It returns false if there is nothing to undo.
+func (h *History) Undo() bool {
+	h.mu.Lock()
+	n := len(h.undo)
+	if n == 0 {
+		h.mu.Unlock()
+		return false
+	}
+	c := h.undo[n-1]
+	h.undo = h.undo[:n-1]
+	h.redo = append(h.redo, c)
+	h.at = time.Time{}
+	// The widgets hold GuiLock when recording, so it must not be taken while h.mu is locked
+	h.mu.Unlock()
+	GuiLock.Lock()
+	c.apply(true)
+	GuiLock.Unlock()
+	if invalidate != nil {
+		Invalidate()
+	}
+	return true
+}
+
+// Redo sets the variable back to the value that was undone.
+// It returns false if there is nothing to redo.
+func (h *History) Redo() bool {
+	h.mu.Lock()
+	n := len(h.redo)
+	if n == 0 {
+		h.mu.Unlock()
+		return false
+	}
+	c := h.redo[n-1]
+	h.redo = h.redo[:n-1]
+	h.undo = append(h.undo, c)
+	h.at = time.Time{}
+	h.mu.Unlock()
+	GuiLock.Lock()
+	c.apply(false)
+	GuiLock.Unlock()
+	if invalidate != nil {
+		Invalidate()
+	}
+	return true
+}
+
+// CanUndo is true when there are changes to undo
+func (h *History) CanUndo() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.undo) > 0
+}
+
+// CanRedo is true when there are undone changes to redo
+func (h *History) CanRedo() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.redo) > 0
+}
+
+// Clear removes all changes, typically after the form is saved.
+func (h *History) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.undo = h.undo[:0]
+	h.redo = h.redo[:0]
+}
+
+// handleUndoKeys will undo/redo form changes on Ctrl+Z, Ctrl+Y and Ctrl+Shift+Z
+// when the keys are not used by the focused widget. It is called after handleShortcuts,
+// so a registered shortcut, like the accelerator of an Edit/Undo menu item, is used instead.
+func handleUndoKeys(gtx C) {
+	if history == nil {
+		return
+	}
+	for {
+		ev, ok := gtx.Event(
+			key.Filter{Name: "Z", Required: key.ModShortcut, Optional: key.ModShift},
+			key.Filter{Name: "Y", Required: key.ModShortcut},
+		)
+		if !ok {
+			break
+		}
+		if e, ok := ev.(key.Event); ok && e.State == key.Press {
+			if e.Name == "Y" || e.Modifiers.Contain(key.ModShift) {
+				history.Redo()
+			} else {
+				history.Undo()
+			}
+		}
+	}
+}
+
+// textHistory keeps snapshots of the text in an edit, used for undo/redo.
+type textHistory struct {
+	states []textState
+	pos    int
+	at     time.Time
+}
+
+type textState struct {
+	text       string
+	start, end int
+}
+
+// maxTextUndo is the number of undo steps kept for each edit
+const maxTextUndo = 100
+
+// reset forgets all history, and starts with the given text.
+func (t *textHistory) reset(text string) {
+	t.states = append(t.states[:0], textState{text: text})
+	t.pos = 0
+	t.at = time.Time{}
+}
+
+// record saves the text if it is changed. Changes made within
+// the undoGroup time are merged into one step.
+func (t *textHistory) record(text string, start, end int, now time.Time) {
+	if len(t.states) == 0 {
+		t.reset(text)
+		return
+	}
+	if t.states[t.pos].text == text {
+		return
+	}
+	t.states = t.states[:t.pos+1]
+	if t.pos > 0 && now.Sub(t.at) < undoGroup {
+		t.states[t.pos] = textState{text, start, end}
+	} else {
+		t.states = append(t.states, textState{text, start, end})
+		if len(t.states) > maxTextUndo {
+			t.states = t.states[1:]
+		}
+		t.pos = len(t.states) - 1
+	}
+	t.at = now
+}
+
+// step moves dir steps back (-1) or forward (+1) in the history, and
+// returns the state to restore.
+func (t *textHistory) step(dir int) (textState, bool) {
+	p := t.pos + dir
+	if p < 0 || p >= len(t.states) {
+		return textState{}, false
+	}
+	t.pos = p
+	t.at = time.Time{}
+	return t.states[p], true
+}
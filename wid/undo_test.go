@@ -0,0 +1,102 @@
+package wid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryMerge(t *testing.T) {
+	h := NewHistory(0)
+	v := 3
+	t0 := time.Now()
+	// Changes within undoGroup are undone in one step
+	v = 4
+	h.record(&v, 3, 4, t0)
+	v = 5
+	h.record(&v, 4, 5, t0.Add(undoGroup/2))
+	if len(h.undo) != 1 {
+		t.Fatalf("%d changes, want 1", len(h.undo))
+	}
+	if !h.Undo() || v != 3 {
+		t.Errorf("undo gave %d, want 3", v)
+	}
+	if !h.Redo() || v != 5 {
+		t.Errorf("redo gave %d, want 5", v)
+	}
+	// A later change is a new step
+	v = 6
+	h.record(&v, 5, 6, t0.Add(3*undoGroup))
+	if len(h.undo) != 2 {
+		t.Errorf("%d changes, want 2", len(h.undo))
+	}
+	// A change back to the old value is removed
+	v = 5
+	h.record(&v, 6, 5, t0.Add(3*undoGroup+undoGroup/2))
+	if len(h.undo) != 1 {
+		t.Errorf("%d changes after going back, want 1", len(h.undo))
+	}
+	// Changes to other variables are not merged
+	w := "a"
+	h.record(&w, "", "a", t0.Add(4*undoGroup))
+	v = 7
+	h.record(&v, 5, 7, t0.Add(4*undoGroup))
+	if len(h.undo) != 3 {
+		t.Errorf("%d changes, want 3", len(h.undo))
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	h := NewHistory(2)
+	v := 0
+	t0 := time.Now()
+	for i := 1; i <= 4; i++ {
+		v = i
+		h.record(&v, i-1, i, t0.Add(time.Duration(i)*2*undoGroup))
+	}
+	if len(h.undo) != 2 {
+		t.Fatalf("%d changes, want 2", len(h.undo))
+	}
+	h.Undo()
+	h.Undo()
+	if v != 2 || h.CanUndo() {
+		t.Errorf("undone to %d, want 2", v)
+	}
+}
+
+func TestHistoryRedo(t *testing.T) {
+	h := NewHistory(0)
+	v := 1
+	t0 := time.Now()
+	v = 2
+	h.record(&v, 1, 2, t0)
+	v = 3
+	h.record(&v, 2, 3, t0.Add(2*undoGroup))
+	h.Undo()
+	if v != 2 || !h.CanRedo() {
+		t.Fatalf("undo gave %d, want 2", v)
+	}
+	// A new change clears the undone changes
+	v = 4
+	h.record(&v, 2, 4, t0.Add(4*undoGroup))
+	if h.CanRedo() || h.Redo() {
+		t.Error("redo after a new change")
+	}
+	if v != 4 {
+		t.Errorf("value %d, want 4", v)
+	}
+	// Edits of a part of a value are merged, and undone in reverse order
+	b := []byte{1, 2}
+	set := func(i int, x byte) func() { return func() { b[i] = x } }
+	b[0] = 5
+	h.recordEdit(&b, set(0, 1), set(0, 5), t0.Add(6*undoGroup))
+	b[1] = 6
+	h.recordEdit(&b, set(1, 2), set(1, 6), t0.Add(6*undoGroup+undoGroup/2))
+	h.Undo()
+	if b[0] != 1 || b[1] != 2 {
+		t.Errorf("undo edit gave %v", b)
+	}
+	h.Redo()
+	if b[0] != 5 || b[1] != 6 {
+		t.Errorf("redo edit gave %v", b)
+	}
+}